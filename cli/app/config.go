@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+)
+
+// ProjectConfig validates the compose files and prints the configuration of
+// the project they define, once merged and interpolated.
+func ProjectConfig(p project.APIProject, c *cli.Context) error {
+	composeProject, ok := p.(*project.Project)
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("Unsupported project type %T", p), 1)
+	}
+
+	switch {
+	case c.Bool("q"):
+		// the project was parsed, the compose files are valid
+	case c.Bool("services"):
+		for _, name := range composeProject.EnabledServices() {
+			fmt.Println(name)
+		}
+	case c.Bool("volumes"):
+		names := []string{}
+		for name := range composeProject.VolumeConfigs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	case c.Bool("hash"):
+		names := c.Args()
+		if len(names) == 0 {
+			names = composeProject.EnabledServices()
+		}
+		for _, name := range names {
+			serviceConfig, ok := composeProject.ServiceConfigs.Get(name)
+			if !ok {
+				return cli.NewExitError(fmt.Sprintf("No such service: %s", name), 1)
+			}
+			fmt.Printf("%s %s\n", name, config.GetServiceHash(name, serviceConfig))
+		}
+	default:
+		data, err := composeProject.MarshalConfig()
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+		os.Stdout.Write(data)
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/codegangsta/cli"
 	"github.com/docker/libcompose/project"
 )
@@ -10,3 +14,22 @@ type ProjectFactory interface {
 	// Create creates a libcompose project from the command line options (codegangsta cli context).
 	Create(c *cli.Context) (project.APIProject, error)
 }
+
+// BackendFactory is a ProjectFactory delegating to the factory of the backend
+// selected with the --backend flag.
+type BackendFactory map[string]ProjectFactory
+
+// Create implements ProjectFactory.Create using the factory of the selected backend.
+func (f BackendFactory) Create(c *cli.Context) (project.APIProject, error) {
+	backend := c.GlobalString("backend")
+	factory, ok := f[backend]
+	if !ok {
+		backends := []string{}
+		for name := range f {
+			backends = append(backends, name)
+		}
+		sort.Strings(backends)
+		return nil, fmt.Errorf("Unknown backend %s, supported backends are %s", backend, strings.Join(backends, ", "))
+	}
+	return factory.Create(c)
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/codegangsta/cli"
 	"github.com/docker/libcompose/cli/app"
 	k8sApp "github.com/docker/libcompose/cli/k8s/app"
-	"github.com/docker/libcompose/project"
 )
 
 // CreateCommand defines the libcompose create subcommand.
@@ -317,6 +316,34 @@ func EventsCommand(factory app.ProjectFactory) cli.Command {
 	}
 }
 
+// ConfigCommand defines the libcompose config subcommand.
+func ConfigCommand(factory app.ProjectFactory) cli.Command {
+	return cli.Command{
+		Name:      "config",
+		Usage:     "Validate and view the compose files, once merged and interpolated",
+		ArgsUsage: "[SERVICE...]",
+		Action:    app.WithProject(factory, app.ProjectConfig),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "q",
+				Usage: "Only validate the configuration, don't print anything",
+			},
+			cli.BoolFlag{
+				Name:  "services",
+				Usage: "Print the names of the enabled services and of their dependencies, one per line",
+			},
+			cli.BoolFlag{
+				Name:  "volumes",
+				Usage: "Print the volume names, one per line",
+			},
+			cli.BoolFlag{
+				Name:  "hash",
+				Usage: "Print the configuration hash of the enabled services and of their dependencies, or of the specified ones",
+			},
+		},
+	}
+}
+
 // VersionCommand defines the libcompose version subcommand.
 func VersionCommand(factory app.ProjectFactory) cli.Command {
 	return cli.Command{
@@ -368,6 +395,122 @@ func KuberCommand(factory app.ProjectFactory) cli.Command {
 						Name:  "yaml, y",
 						Usage: "Generate resource file in yaml format",
 					},
+					cli.StringFlag{
+						Name:  "provider",
+						Usage: "Target platform of the generated objects: kubernetes or openshift",
+						Value: "kubernetes",
+					},
+					cli.StringFlag{
+						Name:  "max-surge",
+						Usage: "Default number (or percentage) of pods created above the desired replicas during a deployment rolling update",
+					},
+					cli.StringFlag{
+						Name:  "max-unavailable",
+						Usage: "Default number (or percentage) of pods that can be unavailable during a deployment rolling update",
+					},
+					cli.StringFlag{
+						Name:  "min-ready-seconds",
+						Usage: "Default number of seconds a new deployment pod must be ready before being considered available",
+					},
+					cli.StringFlag{
+						Name:  "revision-history-limit",
+						Usage: "Default number of old deployment revisions kept for rollbacks",
+					},
+				},
+			},
+			{
+				Name:   "diff",
+				Usage:  "Show the differences between the converted compose file and the objects in kubernetes",
+				Action: app.WithProject(factory, k8sApp.ProjectKuberDiff),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:   "file,f",
+						Usage:  "Specify an alternate compose file (default: docker-compose.yml)",
+						Value:  "docker-compose.yml",
+						EnvVar: "COMPOSE_FILE",
+					},
+					cli.BoolFlag{
+						Name:  "deployment,d",
+						Usage: "Compare deployment resources",
+					},
+					cli.BoolFlag{
+						Name:  "daemonset,ds",
+						Usage: "Compare daemonset resources",
+					},
+					cli.BoolFlag{
+						Name:  "replicaset,rs",
+						Usage: "Compare replicaset resources",
+					},
+					cli.StringFlag{
+						Name:  "provider",
+						Usage: "Target platform of the compared objects: kubernetes or openshift",
+						Value: "kubernetes",
+					},
+					cli.StringFlag{
+						Name:  "max-surge",
+						Usage: "Default number (or percentage) of pods created above the desired replicas during a deployment rolling update",
+					},
+					cli.StringFlag{
+						Name:  "max-unavailable",
+						Usage: "Default number (or percentage) of pods that can be unavailable during a deployment rolling update",
+					},
+					cli.StringFlag{
+						Name:  "min-ready-seconds",
+						Usage: "Default number of seconds a new deployment pod must be ready before being considered available",
+					},
+					cli.StringFlag{
+						Name:  "revision-history-limit",
+						Usage: "Default number of old deployment revisions kept for rollbacks",
+					},
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Generate a docker-compose.yml from Kubernetes manifests",
+				ArgsUsage: "MANIFEST...",
+				Action:    k8sApp.ProjectKuberImport,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "out,o",
+						Usage: "Compose file to write, - for stdout",
+						Value: "docker-compose.yml",
+					},
+				},
+			},
+			{
+				Name:  "rollout",
+				Usage: "Manage the rollout of the deployment of a service",
+				Subcommands: []cli.Command{
+					{
+						Name:      "status",
+						Usage:     "Show the status of the rollout",
+						ArgsUsage: "SERVICE",
+						Action:    k8sApp.ProjectKuberRolloutStatus,
+						Flags: []cli.Flag{
+							cli.BoolTFlag{
+								Name:  "watch,w",
+								Usage: "Watch the status of the rollout until it is done",
+							},
+						},
+					},
+					{
+						Name:      "history",
+						Usage:     "Show the previous revisions of the deployment",
+						ArgsUsage: "SERVICE",
+						Action:    k8sApp.ProjectKuberRolloutHistory,
+					},
+					{
+						Name:      "undo",
+						Usage:     "Roll back to a previous revision of the deployment",
+						ArgsUsage: "SERVICE",
+						Action:    k8sApp.ProjectKuberRolloutUndo,
+						Flags: []cli.Flag{
+							cli.IntFlag{
+								Name:  "to-revision",
+								Usage: "The revision to roll back to, 0 for the previous one",
+							},
+						},
+					},
 				},
 			},
 			{
@@ -380,6 +523,11 @@ func KuberCommand(factory app.ProjectFactory) cli.Command {
 				Usage:  "Get active data in the kubernetes cluster",
 				Action: app.WithProject(factory, k8sApp.ProjectKuberPS),
 				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "output,o",
+						Usage: "Output format: table, wide, json or yaml",
+						Value: "table",
+					},
 					cli.BoolFlag{
 						Name:  "service,svc",
 						Usage: "Get active services",
@@ -390,6 +538,46 @@ func KuberCommand(factory app.ProjectFactory) cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "logs",
+				Usage:     "Get the logs of the pods of the services",
+				ArgsUsage: "[SERVICE...]",
+				Action:    app.WithProject(factory, k8sApp.ProjectKuberLogs),
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "follow,f",
+						Usage: "Follow log output, including the pods started meanwhile",
+					},
+					cli.StringFlag{
+						Name:  "since",
+						Usage: "Only show the logs newer than a relative duration like 5s, 2m or 3h",
+					},
+					cli.IntFlag{
+						Name:  "tail",
+						Usage: "Number of lines to show from the end of the logs, all of them if negative",
+						Value: -1,
+					},
+				},
+			},
+			{
+				Name:      "exec",
+				Usage:     "Execute a command in a running pod of a service",
+				ArgsUsage: "SERVICE COMMAND [ARGS...]",
+				Action:    app.WithProject(factory, k8sApp.ProjectKuberExec),
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "T",
+						Usage: "Disable pseudo-tty allocation",
+					},
+				},
+			},
+			{
+				Name:            "run",
+				Usage:           "Run a one-off command in a new pod of a service",
+				ArgsUsage:       "SERVICE [COMMAND...]",
+				Action:          app.WithProject(factory, k8sApp.ProjectKuberRun),
+				SkipFlagParsing: true,
+			},
 			{
 				Name:   "delete",
 				Usage:  "Remove instantiated services/rc from kubernetes",
@@ -410,17 +598,19 @@ func KuberCommand(factory app.ProjectFactory) cli.Command {
 				},
 			},
 			{
-				Name:   "scale",
-				Usage:  "Globally scale instantiated replication controllers",
-				Action: app.WithProject(factory, k8sApp.ProjectKuberScale),
+				Name:      "scale",
+				Usage:     "Scale the deployments, replica sets and replication controllers of services",
+				ArgsUsage: "SERVICE=NUM...",
+				Action:    app.WithProject(factory, k8sApp.ProjectKuberScale),
 				Flags: []cli.Flag{
-					cli.IntFlag{
-						Name:  "scale",
-						Usage: "New number of replicas",
+					cli.BoolFlag{
+						Name:  "wait,w",
+						Usage: "Wait until the new number of replicas is ready",
 					},
-					cli.StringFlag{
-						Name:  "replicationcontroller,rc",
-						Usage: "A specific replication controller to scale",
+					cli.IntFlag{
+						Name:  "timeout,t",
+						Usage: "Specify how long to wait, in seconds",
+						Value: 300,
 					},
 				},
 			},
@@ -454,10 +644,22 @@ func CommonFlags() []cli.Flag {
 			Value:  &cli.StringSlice{},
 			EnvVar: "COMPOSE_FILE",
 		},
+		cli.StringSliceFlag{
+			Name:   "profile",
+			Usage:  "Enable the services of one or more profiles",
+			Value:  &cli.StringSlice{},
+			EnvVar: "COMPOSE_PROFILES",
+		},
 		cli.StringFlag{
 			Name:   "project-name,p",
 			Usage:  "Specify an alternate project name (default: directory name)",
 			EnvVar: "COMPOSE_PROJECT_NAME",
 		},
+		cli.StringFlag{
+			Name:   "backend",
+			Usage:  "Specify where the project runs, docker or kubernetes",
+			Value:  "docker",
+			EnvVar: "KOMPOSE_BACKEND",
+		},
 	}
 }
@@ -29,6 +29,7 @@ func (p *ProjectFactory) Create(c *cli.Context) (project.APIProject, error) {
 	}
 
 	context.ProjectName = c.GlobalString("project-name")
+	context.Profiles = c.GlobalStringSlice("profile")
 
 	return docker.NewProject(context, nil)
 }
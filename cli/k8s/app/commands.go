@@ -18,41 +18,41 @@ package app
 
 import (
 	"fmt"
-	"math/rand"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
+	"github.com/docker/docker/pkg/term"
 
+	"github.com/docker/libcompose/cli/logger"
+	"github.com/docker/libcompose/kubernetes"
+	"github.com/docker/libcompose/openshift"
 	"github.com/docker/libcompose/project"
-	//project "github.com/skippbox/kompose/project"
 
 	"encoding/json"
 	"io/ioutil"
 
 	"k8s.io/kubernetes/pkg/api"
-	"k8s.io/kubernetes/pkg/api/unversioned"
-	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/api/errors"
 	restclient "k8s.io/kubernetes/pkg/client/restclient"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
-	"k8s.io/kubernetes/pkg/util/intstr"
+	"k8s.io/kubernetes/pkg/runtime"
 
+	candiedyaml "github.com/cloudfoundry-incubator/candiedyaml"
 	"github.com/ghodss/yaml"
 )
 
-const letterBytes = "abcdefghijklmnopqrstuvwxyz0123456789"
-
-func RandStringBytes(n int) string {
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
-	}
-	return string(b)
+// transformers holds the supported `k8s convert` providers.
+var transformers = map[string]kubernetes.Transformer{
+	"kubernetes": &kubernetes.Converter{},
+	"openshift":  &openshift.Converter{},
 }
 
 /* Kubernetes specific configuration */
-func ProjectKuberConfig(p *project.Project, c *cli.Context) {
+func ProjectKuberConfig(p project.APIProject, c *cli.Context) error {
 	url := c.String("host")
 
 	outputFilePath := ".kuberconfig"
@@ -60,87 +60,79 @@ func ProjectKuberConfig(p *project.Project, c *cli.Context) {
 	if err := ioutil.WriteFile(outputFilePath, wurl, 0644); err != nil {
 		logrus.Fatalf("Failed to write k8s api server address to %s: %v", outputFilePath, err)
 	}
+	return nil
 }
 
-func ProjectKuberPS(p *project.Project, c *cli.Context) {
-	server := getK8sServer("")
-	//version := "v1"
-
-	//client := client.NewOrDie(&restclient.Config{Host: server, Version: version})
-	client := client.NewOrDie(&restclient.Config{Host: server})
-	if c.BoolT("svc") {
-		fmt.Printf("%-20s%-20s%-20s%-20s\n", "Name", "Cluster IP", "Ports", "Selectors")
-		for name := range p.Configs {
-			var ports string
-			var selectors string
-			services, err := client.Services(api.NamespaceDefault).Get(name)
-
-			if err != nil {
-				logrus.Debugf("Cannot find service for: ", name)
-			} else {
-
-				for i := range services.Spec.Ports {
-					p := strconv.Itoa(services.Spec.Ports[i].Port)
-					ports += ports + string(services.Spec.Ports[i].Protocol) + "(" + p + "),"
-				}
-
-				for k, v := range services.ObjectMeta.Labels {
-					selectors += selectors + k + "=" + v + ","
-				}
-
-				ports = strings.TrimSuffix(ports, ",")
-				selectors = strings.TrimSuffix(selectors, ",")
-
-				fmt.Printf("%-20s%-20s%-20s%-20s\n", services.ObjectMeta.Name,
-					services.Spec.ClusterIP, ports, selectors)
-			}
+// ProjectKuberPS lists the objects generated for the project services and their pods.
+func ProjectKuberPS(p project.APIProject, c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
 
+	kinds := append(append([]string{}, kubernetes.Kinds...), "Pod")
+	if c.BoolT("svc") || c.BoolT("rc") {
+		kinds = []string{}
+		if c.BoolT("svc") {
+			kinds = append(kinds, "Service")
+		}
+		if c.BoolT("rc") {
+			kinds = append(kinds, "ReplicationController")
 		}
 	}
 
-	if c.BoolT("rc") {
-		fmt.Printf("%-15s%-15s%-30s%-10s%-20s\n", "Name", "Containers", "Images",
-			"Replicas", "Selectors")
-		for name := range p.Configs {
-			var selectors string
-			var containers string
-			var images string
-			rc, err := client.ReplicationControllers(api.NamespaceDefault).Get(name)
-
-			/* Should grab controller, container, image, selector, replicas */
-
-			if err != nil {
-				logrus.Debugf("Cannot find rc for: ", string(name))
-			} else {
-
-				for k, v := range rc.Spec.Selector {
-					selectors += selectors + k + "=" + v + ","
-				}
-
-				for i := range rc.Spec.Template.Spec.Containers {
-					c := rc.Spec.Template.Spec.Containers[i]
-					containers += containers + c.Name + ","
-					images += images + c.Image + ","
-				}
-				selectors = strings.TrimSuffix(selectors, ",")
-				containers = strings.TrimSuffix(containers, ",")
-				images = strings.TrimSuffix(images, ",")
+	listings, err := kubernetes.Ps(client, api.NamespaceDefault, serviceNames(p), kinds)
+	if err != nil {
+		logrus.Fatalf("Failed to list the objects of the project: %v", err)
+	}
 
-				fmt.Printf("%-15s%-15s%-30s%-10d%-20s\n", rc.ObjectMeta.Name, containers,
-					images, rc.Spec.Replicas, selectors)
+	output := c.String("output")
+	switch output {
+	case "table", "wide":
+		first := true
+		for _, listing := range listings {
+			if len(listing.Objects) == 0 {
+				continue
+			}
+			if !first {
+				fmt.Println()
 			}
+			first = false
+			fmt.Printf("%s:\n", listing.Kind)
+			fmt.Print(listing.Infos(p.(*project.Project), output == "wide").String(true))
+		}
+	case "json", "yaml":
+		list := struct {
+			Kind       string           `json:"kind"`
+			APIVersion string           `json:"apiVersion"`
+			Items      []runtime.Object `json:"items"`
+		}{
+			Kind:       "List",
+			APIVersion: "v1",
+			Items:      []runtime.Object{},
+		}
+		for _, listing := range listings {
+			list.Items = append(list.Items, listing.Objects...)
 		}
-	}
 
+		data, err := json.MarshalIndent(list, "", "  ")
+		if output == "yaml" {
+			data, err = yaml.Marshal(list)
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to marshal the objects of the project: %v", err)
+		}
+		fmt.Println(string(data))
+	default:
+		return cli.NewExitError(fmt.Sprintf("Unknown output format %s, supported formats are table, wide, json and yaml", output), 1)
+	}
+	return nil
 }
 
-func ProjectKuberDelete(p *project.Project, c *cli.Context) {
+func ProjectKuberDelete(p project.APIProject, c *cli.Context) error {
 	server := getK8sServer("")
 	//version := "v1"
 	//client := client.NewOrDie(&client.Config{Host: server, Version: version})
 	client := client.NewOrDie(&restclient.Config{Host: server})
 
-	for name := range p.Configs {
+	for _, name := range serviceNames(p) {
 		if len(c.String("name")) > 0 && name != c.String("name") {
 			continue
 		}
@@ -157,562 +149,381 @@ func ProjectKuberDelete(p *project.Project, c *cli.Context) {
 			}
 		}
 	}
+	return nil
 }
 
-func ProjectKuberScale(p *project.Project, c *cli.Context) {
-	server := getK8sServer("")
-	//version := "v1"
-	//client := client.NewOrDie(&client.Config{Host: server, Version: version})
-	client := client.NewOrDie(&restclient.Config{Host: server})
-
-	if c.Int("scale") <= 0 {
-		logrus.Fatalf("Scale must be defined and a positive number")
-	}
-
-	for name := range p.Configs {
-		if len(c.String("rc")) == 0 || c.String("rc") == name {
-			s, err := client.ExtensionsClient.Scales(api.NamespaceDefault).Get("ReplicationController", name)
-			if err != nil {
-				logrus.Fatalf("Error retrieving scaling data: %s\n", err)
-			}
-
-			s.Spec.Replicas = c.Int("scale")
-
-			s, err = client.ExtensionsClient.Scales(api.NamespaceDefault).Update("ReplicationController", s)
-			if err != nil {
-				logrus.Fatalf("Error updating scaling data: %s\n", err)
-			}
+// ProjectKuberScale sets the number of replicas of the controllers of the
+// services given as SERVICE=NUM arguments.
+func ProjectKuberScale(p project.APIProject, c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
 
-			fmt.Printf("Scaling %s to: %d\n", name, s.Spec.Replicas)
+	names := []string{}
+	servicesScale := map[string]int{}
+	for _, arg := range c.Args() {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return cli.NewExitError(fmt.Sprintf("Invalid scale parameter: %s", arg), 2)
 		}
-	}
-}
 
-func ProjectKuberConvert(p *project.Project, c *cli.Context) {
-	//createInstance := true
-	generateYaml := false
-	composeFile := c.String("file")
+		name := kv[0]
 
-	p = project.NewProject(&project.Context{
-		ProjectName: "kube",
-		ComposeFile: composeFile,
-	})
+		count, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Invalid scale parameter: %v", err), 2)
+		}
+		if count < 0 {
+			return cli.NewExitError(fmt.Sprintf("Invalid scale parameter: %s, the number of replicas cannot be negative", arg), 2)
+		}
 
-	if err := p.Parse(); err != nil {
-		logrus.Fatalf("Failed to parse the compose project from %s: %v", composeFile, err)
+		if _, ok := servicesScale[name]; !ok {
+			names = append(names, name)
+		}
+		servicesScale[name] = count
 	}
-
-	//server := getK8sServer("")
-
-	//if c.BoolT("deployment") || c.BoolT("chart") || c.BoolT("daemonset") || c.BoolT("replicaset") {
-	//    createInstance = false
-	//}
-
-	if c.BoolT("yaml") {
-		generateYaml = true
+	if len(names) == 0 {
+		return cli.NewExitError("Please pass arguments in the form: SERVICE=NUM ...", 2)
 	}
 
-	var mServices map[string]api.Service = make(map[string]api.Service)
-	var serviceLinks []string
+	failed := false
+	for _, name := range names {
+		kinds, err := kubernetes.Scale(client, api.NamespaceDefault, name, servicesScale[name])
+		for _, kind := range kinds {
+			fmt.Printf("Scaling %s %s to %d\n", kind, name, servicesScale[name])
+		}
+		if err != nil {
+			logrus.Errorf("Failed to scale %s: %v", name, err)
+			failed = true
+			continue
+		}
 
-	//version := "v1"
-	// create new client
-	//client := client.NewOrDie(&client.Config{Host: server, Version: version})
-	//client := client.NewOrDie(&restclient.Config{Host: server})
-
-	for name, service := range p.Configs {
-		rc := &api.ReplicationController{
-			TypeMeta: unversioned.TypeMeta{
-				Kind:       "ReplicationController",
-				APIVersion: "v1",
-			},
-			ObjectMeta: api.ObjectMeta{
-				Name: name,
-				//Labels: map[string]string{"service": name},
-			},
-			Spec: api.ReplicationControllerSpec{
-				Replicas: 1,
-				Selector: map[string]string{"service": name},
-				Template: &api.PodTemplateSpec{
-					ObjectMeta: api.ObjectMeta{
-					//Labels: map[string]string{"service": name},
-					},
-					Spec: api.PodSpec{
-						Containers: []api.Container{
-							{
-								Name:  name,
-								Image: service.Image,
-							},
-						},
-					},
-				},
-			},
-		}
-		sc := &api.Service{
-			TypeMeta: unversioned.TypeMeta{
-				Kind:       "Service",
-				APIVersion: "v1",
-			},
-			ObjectMeta: api.ObjectMeta{
-				Name: name,
-				//Labels: map[string]string{"service": name},
-			},
-			Spec: api.ServiceSpec{
-				Selector: map[string]string{"service": name},
-			},
-		}
-		dc := &extensions.Deployment{
-			TypeMeta: unversioned.TypeMeta{
-				Kind:       "Deployment",
-				APIVersion: "extensions/v1beta1",
-			},
-			ObjectMeta: api.ObjectMeta{
-				Name:   name,
-				Labels: map[string]string{"service": name},
-			},
-			Spec: extensions.DeploymentSpec{
-				Replicas: 1,
-				Selector: &unversioned.LabelSelector{
-					MatchLabels: map[string]string{"service": name},
-				},
-				//UniqueLabelKey: p.Name,
-				Template: api.PodTemplateSpec{
-					ObjectMeta: api.ObjectMeta{
-						Labels: map[string]string{"service": name},
-					},
-					Spec: api.PodSpec{
-						Containers: []api.Container{
-							{
-								Name:  name,
-								Image: service.Image,
-							},
-						},
-					},
-				},
-			},
-		}
-		ds := &extensions.DaemonSet{
-			TypeMeta: unversioned.TypeMeta{
-				Kind:       "DaemonSet",
-				APIVersion: "extensions/v1beta1",
-			},
-			ObjectMeta: api.ObjectMeta{
-				Name: name,
-			},
-			Spec: extensions.DaemonSetSpec{
-				Template: api.PodTemplateSpec{
-					ObjectMeta: api.ObjectMeta{
-						Name: name,
-					},
-					Spec: api.PodSpec{
-						Containers: []api.Container{
-							{
-								Name:  name,
-								Image: service.Image,
-							},
-						},
-					},
-				},
-			},
-		}
-		rs := &extensions.ReplicaSet{
-			TypeMeta: unversioned.TypeMeta{
-				Kind:       "ReplicaSet",
-				APIVersion: "extensions/v1beta1",
-			},
-			ObjectMeta: api.ObjectMeta{
-				Name: name,
-			},
-			Spec: extensions.ReplicaSetSpec{
-				Replicas: 1,
-				Selector: &unversioned.LabelSelector{
-					MatchLabels: map[string]string{"service": name},
-				},
-				Template: api.PodTemplateSpec{
-					ObjectMeta: api.ObjectMeta{},
-					Spec: api.PodSpec{
-						Containers: []api.Container{
-							{
-								Name:  name,
-								Image: service.Image,
-							},
-						},
-					},
-				},
-			},
-		}
-
-		// Configure the environment variables.
-		var envs []api.EnvVar
-		for _, env := range service.Environment.Slice() {
-			var character string = "="
-			if strings.Contains(env, character) {
-				value := env[strings.Index(env, character)+1:]
-				name := env[0:strings.Index(env, character)]
-				name = strings.TrimSpace(name)
-				value = strings.TrimSpace(value)
-				envs = append(envs, api.EnvVar{
-					Name:  name,
-					Value: value,
-				})
-			} else {
-				character = ":"
-				if strings.Contains(env, character) {
-					var charQuote string = "'"
-					value := env[strings.Index(env, character)+1:]
-					name := env[0:strings.Index(env, character)]
-					name = strings.TrimSpace(name)
-					value = strings.TrimSpace(value)
-					if strings.Contains(value, charQuote) {
-						value = strings.Trim(value, "'")
-					}
-					envs = append(envs, api.EnvVar{
-						Name:  name,
-						Value: value,
-					})
-				} else {
-					logrus.Fatalf("Invalid container env %s for service %s", env, name)
+		if c.Bool("wait") {
+			timeout := time.Duration(c.Int("timeout")) * time.Second
+			for _, kind := range kinds {
+				if err := kubernetes.WaitForScale(client, api.NamespaceDefault, kind, name, servicesScale[name], timeout); err != nil {
+					logrus.Errorf("Failed to scale %s: %v", name, err)
+					failed = true
 				}
 			}
 		}
+	}
 
-		rc.Spec.Template.Spec.Containers[0].Env = envs
-		dc.Spec.Template.Spec.Containers[0].Env = envs
-		ds.Spec.Template.Spec.Containers[0].Env = envs
-		rs.Spec.Template.Spec.Containers[0].Env = envs
-
-		// Configure the container command.
-		var cmds []string
-		for _, cmd := range service.Command.Slice() {
-			cmds = append(cmds, cmd)
-		}
-		rc.Spec.Template.Spec.Containers[0].Command = cmds
-		dc.Spec.Template.Spec.Containers[0].Command = cmds
-		ds.Spec.Template.Spec.Containers[0].Command = cmds
-		rs.Spec.Template.Spec.Containers[0].Command = cmds
-
-		// Configure the container working dir.
-		rc.Spec.Template.Spec.Containers[0].WorkingDir = service.WorkingDir
-		dc.Spec.Template.Spec.Containers[0].WorkingDir = service.WorkingDir
-		ds.Spec.Template.Spec.Containers[0].WorkingDir = service.WorkingDir
-		rs.Spec.Template.Spec.Containers[0].WorkingDir = service.WorkingDir
-
-		// Configure the container volumes.
-		var volumesMount []api.VolumeMount
-		var volumes []api.Volume
-		for _, volume := range service.Volumes {
-			var character string = ":"
-			if strings.Contains(volume, character) {
-				hostDir := volume[0:strings.Index(volume, character)]
-				hostDir = strings.TrimSpace(hostDir)
-				containerDir := volume[strings.Index(volume, character)+1:]
-				containerDir = strings.TrimSpace(containerDir)
-
-				// check if ro/rw mode is defined
-				var readonly bool = true
-				if strings.Index(volume, character) != strings.LastIndex(volume, character) {
-					mode := volume[strings.LastIndex(volume, character)+1:]
-					if strings.Compare(mode, "rw") == 0 {
-						readonly = false
-					}
-					containerDir = containerDir[0:strings.Index(containerDir, character)]
-				}
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
 
-				// volumeName = random string of 20 chars
-				volumeName := RandStringBytes(20)
+func ProjectKuberConvert(p project.APIProject, c *cli.Context) error {
+	generateYaml := c.BoolT("yaml")
+	composeFile := c.String("file")
 
-				volumesMount = append(volumesMount, api.VolumeMount{Name: volumeName, ReadOnly: readonly, MountPath: containerDir})
-				p := &api.HostPathVolumeSource{
-					Path: hostDir,
-				}
-				//p.Path = hostDir
-				volumeSource := api.VolumeSource{HostPath: p}
-				volumes = append(volumes, api.Volume{Name: volumeName, VolumeSource: volumeSource})
+	composeProject, objects := convertComposeProject(c)
+
+	if c.String("provider") == "kubernetes" {
+		groups, _ := kubernetes.Groups(composeProject)
+		for _, group := range groups {
+			if len(group.Services) > 1 {
+				fmt.Println(group)
 			}
 		}
+	}
 
-		rc.Spec.Template.Spec.Containers[0].VolumeMounts = volumesMount
-		dc.Spec.Template.Spec.Containers[0].VolumeMounts = volumesMount
-		ds.Spec.Template.Spec.Containers[0].VolumeMounts = volumesMount
-		rs.Spec.Template.Spec.Containers[0].VolumeMounts = volumesMount
+	for _, obj := range objects {
+		// convert the object to json / yaml
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if generateYaml == true {
+			data, err = yaml.Marshal(obj)
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to marshal the %s %s: %v", kubernetes.Kind(obj), kubernetes.Name(obj), err)
+		}
 
-		rc.Spec.Template.Spec.Volumes = volumes
-		dc.Spec.Template.Spec.Volumes = volumes
-		ds.Spec.Template.Spec.Volumes = volumes
-		rs.Spec.Template.Spec.Volumes = volumes
+		logrus.Debugf("%s\n", data)
 
-		// Configure the container privileged mode
-		if service.Privileged == true {
-			securitycontexts := &api.SecurityContext{
-				Privileged: &service.Privileged,
-			}
-			rc.Spec.Template.Spec.Containers[0].SecurityContext = securitycontexts
-			dc.Spec.Template.Spec.Containers[0].SecurityContext = securitycontexts
-			ds.Spec.Template.Spec.Containers[0].SecurityContext = securitycontexts
-			rs.Spec.Template.Spec.Containers[0].SecurityContext = securitycontexts
-		}
-
-		// Configure the container ports.
-		var ports []api.ContainerPort
-		for _, port := range service.Ports {
-			var character string = ":"
-			if strings.Contains(port, character) {
-				//portNumber := port[0:strings.Index(port, character)]
-				targetPortNumber := port[strings.Index(port, character)+1:]
-				targetPortNumber = strings.TrimSpace(targetPortNumber)
-				targetPortNumberInt, err := strconv.Atoi(targetPortNumber)
-				if err != nil {
-					logrus.Fatalf("Invalid container port %s for service %s", port, name)
-				}
-				ports = append(ports, api.ContainerPort{ContainerPort: targetPortNumberInt})
-			} else {
-				portNumber, err := strconv.Atoi(port)
-				if err != nil {
-					logrus.Fatalf("Invalid container port %s for service %s", port, name)
-				}
-				ports = append(ports, api.ContainerPort{ContainerPort: portNumber})
-			}
+		if err := ioutil.WriteFile(objectFileName(obj, generateYaml), data, 0644); err != nil {
+			logrus.Fatalf("Failed to write %s %s: %v", kubernetes.Kind(obj), kubernetes.Name(obj), err)
 		}
+	}
 
-		rc.Spec.Template.Spec.Containers[0].Ports = ports
-		dc.Spec.Template.Spec.Containers[0].Ports = ports
-		ds.Spec.Template.Spec.Containers[0].Ports = ports
-		rs.Spec.Template.Spec.Containers[0].Ports = ports
-
-		// Configure the service ports.
-		var servicePorts []api.ServicePort
-		for _, port := range service.Ports {
-			var character string = ":"
-			if strings.Contains(port, character) {
-				portNumber := port[0:strings.Index(port, character)]
-				portNumber = strings.TrimSpace(portNumber)
-				targetPortNumber := port[strings.Index(port, character)+1:]
-				targetPortNumber = strings.TrimSpace(targetPortNumber)
-				portNumberInt, err := strconv.Atoi(portNumber)
-				if err != nil {
-					logrus.Fatalf("Invalid container port %s for service %s", port, name)
-				}
-				targetPortNumberInt, err1 := strconv.Atoi(targetPortNumber)
-				if err1 != nil {
-					logrus.Fatalf("Invalid container port %s for service %s", port, name)
-				}
-				var targetPort intstr.IntOrString
-				targetPort.StrVal = targetPortNumber
-				targetPort.IntVal = int32(targetPortNumberInt)
-				servicePorts = append(servicePorts, api.ServicePort{Port: portNumberInt, Name: portNumber, Protocol: "TCP", TargetPort: targetPort})
-			} else {
-				portNumber, err := strconv.Atoi(port)
-				if err != nil {
-					logrus.Fatalf("Invalid container port %s for service %s", port, name)
-				}
-				var targetPort intstr.IntOrString
-				targetPort.StrVal = strconv.Itoa(portNumber)
-				targetPort.IntVal = int32(portNumber)
-				servicePorts = append(servicePorts, api.ServicePort{Port: portNumber, Name: strconv.Itoa(portNumber), Protocol: "TCP", TargetPort: targetPort})
+	/* Need to iterate through one more time to ensure we capture all service/rc */
+	for _, name := range kubernetes.ServiceNames(composeProject) {
+		if c.BoolT("chart") {
+			err := generateHelm(composeFile, name)
+			if err != nil {
+				logrus.Fatalf("Failed to create Chart data: %s\n", err)
 			}
 		}
-		sc.Spec.Ports = servicePorts
-
-		// Configure label
-		labels := map[string]string{"service": name}
-		for key, value := range service.Labels.MapParts() {
-			labels[key] = value
-		}
-		rc.Spec.Template.ObjectMeta.Labels = labels
-		dc.Spec.Template.ObjectMeta.Labels = labels
-		ds.Spec.Template.ObjectMeta.Labels = labels
-		rs.Spec.Template.ObjectMeta.Labels = labels
-
-		rc.ObjectMeta.Labels = labels
-		dc.ObjectMeta.Labels = labels
-		ds.ObjectMeta.Labels = labels
-		rs.ObjectMeta.Labels = labels
-
-		sc.ObjectMeta.Labels = labels
-
-		// Configure the container restart policy.
-		switch service.Restart {
-		case "", "always":
-			rc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyAlways
-			dc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyAlways
-			ds.Spec.Template.Spec.RestartPolicy = api.RestartPolicyAlways
-			rs.Spec.Template.Spec.RestartPolicy = api.RestartPolicyAlways
-		case "no":
-			rc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyNever
-			dc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyNever
-			ds.Spec.Template.Spec.RestartPolicy = api.RestartPolicyNever
-			rs.Spec.Template.Spec.RestartPolicy = api.RestartPolicyNever
-		case "on-failure":
-			rc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyOnFailure
-			dc.Spec.Template.Spec.RestartPolicy = api.RestartPolicyOnFailure
-			ds.Spec.Template.Spec.RestartPolicy = api.RestartPolicyOnFailure
-			rs.Spec.Template.Spec.RestartPolicy = api.RestartPolicyOnFailure
-		default:
-			logrus.Fatalf("Unknown restart policy %s for service %s", service.Restart, name)
-		}
-
-		// convert datarc to json / yaml
-		datarc, err := json.MarshalIndent(rc, "", "  ")
-		if generateYaml == true {
-			datarc, err = yaml.Marshal(rc)
-		}
+	}
 
-		if err != nil {
-			logrus.Fatalf("Failed to marshal the replication controller: %v", err)
-		}
-		logrus.Debugf("%s\n", datarc)
+	return nil
+}
 
-		// convert datadc to json / yaml
-		datadc, err := json.MarshalIndent(dc, "", "  ")
-		if generateYaml == true {
-			datadc, err = yaml.Marshal(dc)
-		}
+// ProjectKuberLogs streams the logs of the pods of the services given as
+// arguments, or of all the project services.
+func ProjectKuberLogs(p project.APIProject, c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
+
+	names := serviceNames(p)
+	if len(c.Args()) > 0 {
+		names = c.Args()
+	}
 
+	opts := kubernetes.LogOptions{
+		Follow: c.Bool("follow"),
+		Tail:   int64(c.Int("tail")),
+	}
+	if since := c.String("since"); since != "" {
+		duration, err := time.ParseDuration(since)
 		if err != nil {
-			logrus.Fatalf("Failed to marshal the deployment container: %v", err)
+			return cli.NewExitError(fmt.Sprintf("Invalid duration %s: %v", since, err), 1)
 		}
+		opts.Since = duration
+	}
 
-		logrus.Debugf("%s\n", datadc)
+	if err := kubernetes.Logs(client, api.NamespaceDefault, names, opts, logger.NewColorLoggerFactory()); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
 
-		//convert datads to json / yaml
-		datads, err := json.MarshalIndent(ds, "", "  ")
-		if generateYaml == true {
-			datads, err = yaml.Marshal(ds)
-		}
+// ProjectKuberExec executes a command in a ready pod of a service.
+func ProjectKuberExec(p project.APIProject, c *cli.Context) error {
+	if len(c.Args()) < 2 {
+		return cli.NewExitError("Please pass arguments in the form: SERVICE COMMAND [ARGS...]", 1)
+	}
+	name := c.Args()[0]
+	command := c.Args()[1:]
+
+	config := &restclient.Config{Host: getK8sServer("")}
+	client := client.NewOrDie(config)
+	pod, err := kubernetes.ReadyPod(client, api.NamespaceDefault, name)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
 
+	stdinFd, isTerminal := term.GetFdInfo(os.Stdin)
+	tty := isTerminal && !c.Bool("T")
+	if tty {
+		state, err := term.SetRawTerminal(stdinFd)
 		if err != nil {
-			logrus.Fatalf("Failed to marshal the daemonSet: %v", err)
+			return cli.NewExitError(err.Error(), 1)
 		}
+		defer term.RestoreTerminal(stdinFd, state)
+	}
 
-		logrus.Debugf("%s\n", datads)
+	err = kubernetes.Exec(client, config, api.NamespaceDefault, pod, command, os.Stdin, os.Stdout, os.Stderr, tty)
+	if exitErr, ok := err.(*kubernetes.ExitError); ok {
+		return cli.NewExitError(exitErr.Message, exitErr.ExitCode)
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return nil
+}
 
-		//convert datars to json / yaml
-		datars, err := json.MarshalIndent(rs, "", "  ")
-		if generateYaml == true {
-			datads, err = yaml.Marshal(rs)
-		}
+// ProjectKuberRun runs a one-off command in a new pod of a service, attached
+// to the standard streams and removed once it terminates.
+func ProjectKuberRun(p project.APIProject, c *cli.Context) error {
+	if len(c.Args()) == 0 {
+		logrus.Fatal("No service specified")
+	}
+	name := c.Args()[0]
 
+	service, ok := p.(*project.Project).ServiceConfigs.Get(name)
+	if !ok {
+		return cli.NewExitError(fmt.Sprintf("No such service: %s", name), 1)
+	}
+
+	pod, err := kubernetes.OneOffPod(name, service, c.Args()[1:])
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	stdinFd, tty := term.GetFdInfo(os.Stdin)
+	if tty {
+		state, err := term.SetRawTerminal(stdinFd)
 		if err != nil {
-			logrus.Fatalf("Failed to marshal the replicaSet: %v", err)
+			return cli.NewExitError(err.Error(), 1)
 		}
+		defer term.RestoreTerminal(stdinFd, state)
+	}
 
-		logrus.Debugf("%s\n", datars)
+	config := &restclient.Config{Host: getK8sServer("")}
+	client := client.NewOrDie(config)
+	exitCode, err := kubernetes.Run(client, config, api.NamespaceDefault, pod, os.Stdin, os.Stdout, os.Stderr, tty)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	return cli.NewExitError("", exitCode)
+}
 
-		mServices[name] = *sc
+// ProjectKuberDiff prints the differences between the converted compose file
+// and the objects live in the cluster. It exits with 1 if there are differences.
+func ProjectKuberDiff(p project.APIProject, c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
 
-		if len(service.Links.Slice()) > 0 {
-			for i := 0; i < len(service.Links.Slice()); i++ {
-				var data string = service.Links.Slice()[i]
-				if len(serviceLinks) == 0 {
-					serviceLinks = append(serviceLinks, data)
-				} else {
-					for _, v := range serviceLinks {
-						if v != data {
-							serviceLinks = append(serviceLinks, data)
-						}
-					}
-				}
-			}
+	composeProject, objects := convertComposeProject(c)
+
+	supported := map[string]bool{}
+	for _, kind := range kubernetes.Kinds {
+		supported[kind] = true
+	}
+
+	differs := false
+	converted := map[string]bool{}
+	for _, obj := range objects {
+		kind, name := kubernetes.Kind(obj), kubernetes.Name(obj)
+		converted[kind+"/"+name] = true
+		if !supported[kind] {
+			logrus.Warnf("Cannot compare %s %s, %s objects are not supported", kind, name, kind)
+			continue
 		}
 
-		// call create RC api
-		//if createInstance == true {
-		//    rcCreated, err := client.ReplicationControllers(api.NamespaceDefault).Create(rc)
-		//    if err != nil {
-		//        fmt.Println(err)
-		//    }
-		//    logrus.Debugf("%s\n", rcCreated)
-		//}
+		live, err := kubernetes.Get(client, api.NamespaceDefault, kind, name)
+		if errors.IsNotFound(err) {
+			fmt.Printf("%s/%s would be created\n", kind, name)
+			differs = true
+			continue
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to get %s %s: %v", kind, name, err)
+		}
 
-		fileRC := fmt.Sprintf("%s-rc.json", name)
-		if generateYaml == true {
-			fileRC = fmt.Sprintf("%s-rc.yaml", name)
+		diff, err := kubernetes.Diff(obj, live)
+		if err != nil {
+			logrus.Fatalf("Failed to compare %s %s: %v", kind, name, err)
 		}
-		if err := ioutil.WriteFile(fileRC, []byte(datarc), 0644); err != nil {
-			logrus.Fatalf("Failed to write replication controller: %v", err)
+		if diff != "" {
+			fmt.Print(diff)
+			differs = true
 		}
+	}
 
-		/* Create the deployment container */
-		if c.BoolT("deployment") {
-			fileDC := fmt.Sprintf("%s-deployment.json", name)
-			if generateYaml == true {
-				fileDC = fmt.Sprintf("%s-deployment.yaml", name)
+	for _, kind := range kubernetes.Kinds {
+		live, err := kubernetes.List(client, api.NamespaceDefault, kind)
+		if err != nil {
+			logrus.Fatalf("Failed to list the %s objects: %v", kind, err)
+		}
+		for _, obj := range live {
+			meta, err := api.ObjectMetaFor(obj)
+			if err != nil || !kubernetes.InProject(*meta, composeProject.Name) {
+				continue
 			}
-			if err := ioutil.WriteFile(fileDC, []byte(datadc), 0644); err != nil {
-				logrus.Fatalf("Failed to write deployment container: %v", err)
+			if name := meta.Name; !converted[kind+"/"+name] {
+				fmt.Printf("%s/%s is orphaned, it is no longer generated from the compose file\n", kind, name)
+				differs = true
 			}
 		}
+	}
 
-		/* Create the daemonset container */
-		if c.BoolT("daemonset") {
-			fileDS := fmt.Sprintf("%s-daemonset.json", name)
-			if generateYaml == true {
-				fileDS = fmt.Sprintf("%s-daemonset.yaml", name)
-			}
-			if err := ioutil.WriteFile(fileDS, []byte(datads), 0644); err != nil {
-				logrus.Fatalf("Failed to write daemonset: %v", err)
-			}
+	if differs {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+// ProjectKuberRolloutStatus waits for the rollout of the Deployment of a service to complete.
+func ProjectKuberRolloutStatus(c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
+	name := rolloutService(c)
+
+	lastMessage := ""
+	for {
+		deployment, err := client.Extensions().Deployments(api.NamespaceDefault).Get(name)
+		if err != nil {
+			logrus.Fatalf("Failed to get deployment %s: %v", name, err)
 		}
 
-		/* Create the replicaset container */
-		if c.BoolT("replicaset") {
-			fileRS := fmt.Sprintf("%s-replicaset.json", name)
-			if generateYaml == true {
-				fileRS = fmt.Sprintf("%s-replicaset.yaml", name)
-			}
-			if err := ioutil.WriteFile(fileRS, []byte(datars), 0644); err != nil {
-				logrus.Fatalf("Failed to write replicaset: %v", err)
-			}
+		message, done := kubernetes.RolloutStatus(deployment)
+		if message != lastMessage {
+			fmt.Println(message)
+			lastMessage = message
+		}
+		if done || !c.BoolT("watch") {
+			return nil
 		}
+		time.Sleep(time.Second)
+	}
+}
 
-		for k, v := range mServices {
-			for i := 0; i < len(serviceLinks); i++ {
-				//if serviceLinks[i] == k {
-				// call create SVC api
-				//if createInstance == true {
-				//    scCreated, err := client.Services(api.NamespaceDefault).Create(&v)
-				//    if err != nil {
-				//        fmt.Println(err)
-				//    }
-				//    logrus.Debugf("%s\n", scCreated)
-				//}
-
-				// convert datasvc to json / yaml
-				datasvc, er := json.MarshalIndent(v, "", "  ")
-				if generateYaml == true {
-					datasvc, er = yaml.Marshal(v)
-				}
-				if er != nil {
-					logrus.Fatalf("Failed to marshal the service controller: %v", er)
-				}
+// ProjectKuberRolloutHistory lists the revisions of the Deployment of a service.
+func ProjectKuberRolloutHistory(c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
+	name := rolloutService(c)
 
-				logrus.Debugf("%s\n", datasvc)
+	deployment, err := client.Extensions().Deployments(api.NamespaceDefault).Get(name)
+	if err != nil {
+		logrus.Fatalf("Failed to get deployment %s: %v", name, err)
+	}
 
-				fileSVC := fmt.Sprintf("%s-svc.json", k)
-				if generateYaml == true {
-					fileSVC = fmt.Sprintf("%s-svc.yaml", k)
-				}
+	revisions, err := kubernetes.RolloutHistory(client, api.NamespaceDefault, deployment)
+	if err != nil {
+		logrus.Fatalf("Failed to get the history of deployment %s: %v", name, err)
+	}
 
-				if err := ioutil.WriteFile(fileSVC, []byte(datasvc), 0644); err != nil {
-					logrus.Fatalf("Failed to write service controller: %v", err)
-				}
-				//}
-			}
+	fmt.Printf("%-10s%-40s%s\n", "Revision", "Images", "Change-Cause")
+	for _, revision := range revisions {
+		changeCause := revision.ChangeCause
+		if changeCause == "" {
+			changeCause = "<none>"
 		}
+		fmt.Printf("%-10d%-40s%s\n", revision.Number, strings.Join(revision.Images, ","), changeCause)
 	}
+	return nil
+}
 
-	/* Need to iterate through one more time to ensure we capture all service/rc */
-	for name := range p.Configs {
-		if c.BoolT("chart") {
-			err := generateHelm(composeFile, name)
-			if err != nil {
-				logrus.Fatalf("Failed to create Chart data: %s\n", err)
-			}
+// ProjectKuberRolloutUndo rolls the Deployment of a service back to a previous revision.
+func ProjectKuberRolloutUndo(c *cli.Context) error {
+	client := client.NewOrDie(&restclient.Config{Host: getK8sServer("")})
+	name := rolloutService(c)
+
+	if err := kubernetes.RolloutUndo(client, api.NamespaceDefault, name, int64(c.Int("to-revision"))); err != nil {
+		logrus.Fatalf("Failed to roll back deployment %s: %v", name, err)
+	}
+	fmt.Printf("Rolled back %s\n", name)
+	return nil
+}
+
+// ProjectKuberImport generates a compose file from the Kubernetes manifests given as arguments.
+func ProjectKuberImport(c *cli.Context) error {
+	if len(c.Args()) == 0 {
+		return cli.NewExitError("Please specify the Kubernetes manifests to import", 1)
+	}
+
+	manifests := [][]byte{}
+	for _, file := range c.Args() {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			logrus.Fatalf("Failed to read %s: %v", file, err)
 		}
+		manifests = append(manifests, data)
 	}
+
+	cfg, warnings, err := kubernetes.Import(manifests...)
+	if err != nil {
+		logrus.Fatalf("Failed to import the Kubernetes manifests: %v", err)
+	}
+	for _, warning := range warnings {
+		logrus.Warn(warning)
+	}
+
+	data, err := candiedyaml.Marshal(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to marshal the compose file: %v", err)
+	}
+
+	out := c.String("out")
+	if out == "-" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := ioutil.WriteFile(out, data, 0644); err != nil {
+		logrus.Fatalf("Failed to write %s: %v", out, err)
+	}
+	return nil
 }
 
-func ProjectKuberUp(p *project.Project, c *cli.Context) {
+func ProjectKuberUp(p project.APIProject, c *cli.Context) error {
 	server := getK8sServer("")
 	client := client.NewOrDie(&restclient.Config{Host: server})
 
@@ -750,7 +561,7 @@ func ProjectKuberUp(p *project.Project, c *cli.Context) {
 			if err != nil {
 				fmt.Println(err)
 			}
-			logrus.Debugf("%s\n", scCreated)
+			logrus.Debugf("%v\n", scCreated)
 		}
 	}
 
@@ -783,8 +594,8 @@ func ProjectKuberUp(p *project.Project, c *cli.Context) {
 			if err != nil {
 				fmt.Println(err)
 			}
-			logrus.Debugf("%s\n", rcCreated)
+			logrus.Debugf("%v\n", rcCreated)
 		}
 	}
-
+	return nil
 }
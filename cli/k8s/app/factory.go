@@ -0,0 +1,39 @@
+package app
+
+import (
+	"os"
+
+	"github.com/codegangsta/cli"
+	restclient "k8s.io/kubernetes/pkg/client/restclient"
+
+	"github.com/docker/libcompose/cli/logger"
+	"github.com/docker/libcompose/kubernetes"
+	"github.com/docker/libcompose/project"
+)
+
+// ProjectFactory is a struct that holds the app.ProjectFactory implementation
+// running the projects on the Kubernetes cluster of the .kuberconfig file.
+type ProjectFactory struct {
+}
+
+// Create implements ProjectFactory.Create using a Kubernetes client.
+func (p *ProjectFactory) Create(c *cli.Context) (project.APIProject, error) {
+	context := &kubernetes.Context{}
+	context.LoggerFactory = logger.NewColorLoggerFactory()
+
+	context.ComposeFiles = c.GlobalStringSlice("file")
+
+	if len(context.ComposeFiles) == 0 {
+		context.ComposeFiles = []string{"docker-compose.yml"}
+		if _, err := os.Stat("docker-compose.override.yml"); err == nil {
+			context.ComposeFiles = append(context.ComposeFiles, "docker-compose.override.yml")
+		}
+	}
+
+	context.ProjectName = c.GlobalString("project-name")
+	context.Profiles = c.GlobalStringSlice("profile")
+
+	context.Config = &restclient.Config{Host: getK8sServer("")}
+
+	return kubernetes.NewProject(context, nil)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
@@ -9,6 +10,12 @@ import (
 	"text/template"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/codegangsta/cli"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/kubernetes"
+	"github.com/docker/libcompose/lookup"
+	"github.com/docker/libcompose/project"
 )
 
 /* Ancilliary helper functions to interface with the commands interface */
@@ -38,6 +45,110 @@ func getK8sServer(file string) string {
 	return server
 }
 
+/**
+ * Parse the given compose file into a libcompose project, without any
+ * docker specific runtime, enabling the given profiles.
+ */
+func newComposeProject(composeFile string, profiles []string) (*project.Project, error) {
+	p := project.NewProject(&project.Context{
+		ProjectName:       "kube",
+		ComposeFiles:      []string{composeFile},
+		ResourceLookup:    &lookup.RemoteConfigLookup{},
+		EnvironmentLookup: &lookup.OsEnvLookup{},
+		Profiles:          profiles,
+	}, nil, nil)
+
+	if err := p.Parse(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/**
+ * Convert the compose file given with --file into the objects of the provider
+ * selected with --provider.
+ */
+func convertComposeProject(c *cli.Context) (*project.Project, []runtime.Object) {
+	composeFile := c.String("file")
+
+	composeProject, err := newComposeProject(composeFile, c.GlobalStringSlice("profile"))
+	if err != nil {
+		logrus.Fatalf("Failed to parse the compose project from %s: %v", composeFile, err)
+	}
+
+	provider := c.String("provider")
+	transformer, ok := transformers[provider]
+	if !ok {
+		logrus.Fatalf("Unknown provider %s, supported providers are kubernetes and openshift", provider)
+	}
+
+	opts := kubernetes.ConvertOptions{
+		CreateRC:         true,
+		CreateDeployment: c.BoolT("deployment"),
+		CreateDaemonSet:  c.BoolT("daemonset"),
+		CreateReplicaSet: c.BoolT("replicaset"),
+		Rollout: kubernetes.RolloutOptions{
+			MaxSurge:             c.String("max-surge"),
+			MaxUnavailable:       c.String("max-unavailable"),
+			MinReadySeconds:      c.String("min-ready-seconds"),
+			RevisionHistoryLimit: c.String("revision-history-limit"),
+		},
+	}
+
+	objects, err := transformer.Transform(composeProject, opts)
+	if err != nil {
+		logrus.Fatalf("Failed to convert the compose project from %s: %v", composeFile, err)
+	}
+	return composeProject, objects
+}
+
+/**
+ * Retrieve the service given as argument to the rollout commands.
+ */
+func rolloutService(c *cli.Context) string {
+	if len(c.Args()) != 1 {
+		logrus.Fatalf("Please pass the name of the service whose deployment to roll out")
+	}
+	return c.Args()[0]
+}
+
+/**
+ * Retrieve the sorted service names of the project the commands act on.
+ */
+func serviceNames(p project.APIProject) []string {
+	composeProject, ok := p.(*project.Project)
+	if !ok {
+		logrus.Fatalf("Unsupported project type %T", p)
+	}
+	return kubernetes.ServiceNames(composeProject)
+}
+
+/* File name suffixes of the generated objects, by kind */
+var kindSuffixes = map[string]string{
+	"ReplicationController":   "rc",
+	"Service":                 "svc",
+	"HorizontalPodAutoscaler": "hpa",
+}
+
+/**
+ * Build the name of the file a generated object is written to, like
+ * web-rc.json or web-deployment.yaml.
+ */
+func objectFileName(obj runtime.Object, generateYaml bool) string {
+	kind := kubernetes.Kind(obj)
+	suffix, ok := kindSuffixes[kind]
+	if !ok {
+		suffix = strings.ToLower(kind)
+	}
+
+	extension := "json"
+	if generateYaml {
+		extension = "yaml"
+	}
+
+	return fmt.Sprintf("%s-%s.%s", kubernetes.Name(obj), suffix, extension)
+}
+
 /**
  * Generate Helm Chart configuration
  */
@@ -7,11 +7,15 @@ import (
 	cliApp "github.com/docker/libcompose/cli/app"
 	"github.com/docker/libcompose/cli/command"
 	dockerApp "github.com/docker/libcompose/cli/docker/app"
+	k8sApp "github.com/docker/libcompose/cli/k8s/app"
 	"github.com/docker/libcompose/version"
 )
 
 func main() {
-	factory := &dockerApp.ProjectFactory{}
+	factory := cliApp.BackendFactory{
+		"docker":     &dockerApp.ProjectFactory{},
+		"kubernetes": &k8sApp.ProjectFactory{},
+	}
 
 	app := cli.NewApp()
 	app.Name = "kompose"
@@ -23,6 +27,7 @@ func main() {
 	app.Flags = append(command.CommonFlags(), dockerApp.DockerClientFlags()...)
 	app.Commands = []cli.Command{
 		command.BuildCommand(factory),
+		command.ConfigCommand(factory),
 		command.CreateCommand(factory),
 		command.EventsCommand(factory),
 		command.DownCommand(factory),
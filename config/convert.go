@@ -38,6 +38,8 @@ func ConvertServices(v1Services map[string]*ServiceConfigV1) (map[string]*Servic
 		v2Services[name].Build = replacementFields[name].Build
 		v2Services[name].Logging = replacementFields[name].Logging
 		v2Services[name].NetworkMode = replacementFields[name].NetworkMode
+		v2Services[name].Extensions = v1Services[name].Extensions
+		v2Services[name].Sources = v1Services[name].Sources
 	}
 
 	return v2Services, nil
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+)
+
+// extendsLink is a service of a file in a chain of extends.
+type extendsLink struct {
+	file    string
+	service string
+}
+
+func (link extendsLink) String() string {
+	return fmt.Sprintf("%s in %s", link.service, link.file)
+}
+
+// is returns whether two links are the same service of the same file, the
+// local files being compared by their absolute path.
+func (link extendsLink) is(other extendsLink) bool {
+	return link.service == other.service && absFile(link.file) == absFile(other.file)
+}
+
+func absFile(file string) string {
+	if file == "-" || strings.Contains(file, "://") || strings.HasPrefix(file, "git@") {
+		return file
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
+
+// checkExtendsCycle returns an error listing the chain of extends if the
+// service of the file it extends is already in the chain.
+func checkExtendsCycle(chain []extendsLink, base extendsLink) error {
+	for i, link := range chain {
+		if !link.is(base) {
+			continue
+		}
+		var links []string
+		for _, link := range append(chain[i:], base) {
+			links = append(links, link.String())
+		}
+		return fmt.Errorf("Circular reference: %s", strings.Join(links, " extends "))
+	}
+	return nil
+}
+
+// parseService parses the service name of the file inFile with the parser of
+// the version of the file.
+func parseService(version string, resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, l *locator, name string, serviceData RawService, datas RawServiceMap, options *ParseOptions, chain []extendsLink) (RawService, map[string][]Position, error) {
+	if version == "1" {
+		return parseV1(resourceLookup, environmentLookup, inFile, l, name, serviceData, datas, options, chain)
+	}
+	return parseV2(version, resourceLookup, environmentLookup, inFile, l, name, serviceData, datas, options, chain)
+}
+
+// parseExtends merges the service name of the file inFile, whose keys come
+// from sources, into the service it extends, if any. The service is in the
+// format of the schema version, and the extended service is converted to it
+// when only one of their files is a version 1 one. chain holds the services
+// extending this one.
+func parseExtends(version string, resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, l *locator, name string, serviceData RawService, sources map[string][]Position, datas RawServiceMap, options *ParseOptions, chain []extendsLink) (RawService, map[string][]Position, error) {
+	value, ok := serviceData["extends"]
+	if !ok {
+		return serviceData, sources, nil
+	}
+
+	mapValue, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return serviceData, sources, nil
+	}
+
+	if resourceLookup == nil {
+		return nil, nil, fmt.Errorf("Can not use extends in file %s no mechanism provided to files", inFile)
+	}
+
+	file := asString(mapValue["file"])
+	service := asString(mapValue["service"])
+
+	if service == "" {
+		return serviceData, sources, nil
+	}
+
+	chain = append(chain[:len(chain):len(chain)], extendsLink{file: inFile, service: name})
+
+	var baseService RawService
+	var baseSources map[string][]Position
+	var err error
+
+	baseFile := inFile
+	baseVersion := version
+
+	if file == "" {
+		if err := checkExtendsCycle(chain, extendsLink{file: inFile, service: service}); err != nil {
+			return nil, nil, errors.New(locate(l.service(name, "extends"), err.Error()))
+		}
+		if serviceData, ok := datas[service]; ok {
+			baseService, baseSources, err = parseService(version, resourceLookup, environmentLookup, inFile, l, service, serviceData, datas, options, chain)
+		} else {
+			return nil, nil, errors.New(locate(l.service(name, "extends", "service"), fmt.Sprintf("Failed to find service %s to extend", service)))
+		}
+	} else {
+		bytes, resolved, err := resourceLookup.Lookup(file, inFile)
+		if err != nil {
+			logrus.Errorf("Failed to lookup file %s: %v", file, err)
+			return nil, nil, err
+		}
+		baseFile = resolved
+
+		if err := checkExtendsCycle(chain, extendsLink{file: resolved, service: service}); err != nil {
+			return nil, nil, errors.New(locate(l.service(name, "extends"), err.Error()))
+		}
+
+		var baseRawServices RawServiceMap
+		var baseLocator *locator
+		baseVersion, baseRawServices, baseLocator, err = readExtendedFile(environmentLookup, resolved, bytes, options)
+		if err != nil {
+			return nil, nil, extendedError(err, l, name)
+		}
+
+		baseService, ok = baseRawServices[service]
+		if !ok {
+			return nil, nil, errors.New(locate(l.service(name, "extends", "service"), fmt.Sprintf("Failed to find service %s in file %s", service, file)))
+		}
+
+		baseService, baseSources, err = parseService(baseVersion, resourceLookup, environmentLookup, resolved, baseLocator, service, baseService, baseRawServices, options, chain)
+		if err != nil {
+			return nil, nil, extendedError(err, l, name)
+		}
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseService = clone(baseService)
+
+	// the 2.x and 3.x services share their format
+	if (baseVersion == "1") != (version == "1") {
+		logrus.Debugf("Converting service %s of %s from version %s to version %s", service, baseFile, baseVersion, version)
+		if version == "1" {
+			baseService, baseSources = convertServiceToV1(service, baseService, baseSources)
+		} else {
+			baseService, baseSources = convertServiceFromV1(baseService, baseSources)
+		}
+	}
+
+	logrus.Debugf("Merging %#v, %#v", baseService, serviceData)
+
+	for _, k := range noMerge {
+		if _, ok := baseService[k]; ok {
+			source := file
+			if source == "" {
+				source = inFile
+			}
+			return nil, nil, errors.New(locate(l.service(name, "extends"), fmt.Sprintf("Cannot extend service '%s' in %s: services with '%s' cannot be extended", service, source, k)+describeSources(k, baseSources[k])))
+		}
+	}
+
+	reportExtends(chain[len(chain)-1], extendsLink{file: baseFile, service: service}, baseService, serviceData)
+
+	tags := l.serviceTags(name)
+	baseService = mergeConfig(baseService, serviceData, tags)
+
+	logrus.Debugf("Merged result %#v", baseService)
+
+	return baseService, mergeSources(baseSources, sources, tags), nil
+}
+
+// readExtendedFile reads the services of a file with extended services, and
+// returns the version of the file and the locator of its values. The services
+// are interpolated, preprocessed, and validated for version 1 files.
+func readExtendedFile(environmentLookup EnvironmentLookup, file string, bytes []byte, options *ParseOptions) (string, RawServiceMap, *locator, error) {
+	var config Config
+	if err := yaml.Unmarshal(bytes, &config); err != nil {
+		return "", nil, nil, err
+	}
+
+	version, err := schemaVersion(config.Version)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var datas RawServiceMap
+	var l *locator
+	if version == "1" {
+		l = newLocator(file, bytes)
+		if datas, err = servicesV1(bytes, l); err != nil {
+			return "", nil, nil, err
+		}
+	} else {
+		l = newLocator(file, bytes, "services")
+		datas = config.Services
+	}
+	resolveMergeKeys(datas)
+	l.reset(datas)
+
+	if options.Interpolate {
+		if err := Interpolate(environmentLookup, &datas); err != nil {
+			return "", nil, nil, l.wrap(err)
+		}
+	}
+
+	if options.Preprocess != nil {
+		datas, err = options.Preprocess(datas)
+		if err != nil {
+			return "", nil, nil, err
+		}
+	}
+
+	if options.Validate && version == "1" {
+		if err := validate(datas, l); err != nil {
+			return "", nil, nil, err
+		}
+	}
+
+	return version, datas, l, nil
+}
+
+// convertServiceFromV1 converts a service of a version 1 file, and the
+// sources of its keys, to the format of the version 2 and 3 files.
+func convertServiceFromV1(serviceData RawService, sources map[string][]Position) (RawService, map[string][]Position) {
+	renames := map[string]string{}
+
+	build := map[interface{}]interface{}{}
+	for key, option := range map[string]string{"build": "context", "dockerfile": "dockerfile"} {
+		if value, ok := serviceData[key]; ok {
+			build[option] = value
+			delete(serviceData, key)
+			renames[key] = "build"
+		}
+	}
+	if len(build) > 0 {
+		serviceData["build"] = build
+	}
+
+	logging := map[interface{}]interface{}{}
+	for key, option := range map[string]string{"log_driver": "driver", "log_opt": "options"} {
+		if value, ok := serviceData[key]; ok {
+			logging[option] = value
+			delete(serviceData, key)
+			renames[key] = "logging"
+		}
+	}
+	if len(logging) > 0 {
+		serviceData["logging"] = logging
+	}
+
+	if net, ok := serviceData["net"]; ok {
+		serviceData["network_mode"] = net
+		delete(serviceData, "net")
+		renames["net"] = "network_mode"
+	}
+
+	return serviceData, renameSources(sources, renames)
+}
+
+// convertServiceToV1 converts a service of a version 2 or 3 file, and the
+// sources of its keys, to the format of the version 1 files. The keys version
+// 1 does not support are dropped with a warning.
+func convertServiceToV1(name string, serviceData RawService, sources map[string][]Position) (RawService, map[string][]Position) {
+	serviceData = normalizeService(name, serviceData)
+	renames := map[string]string{}
+
+	if build, ok := serviceData["build"].(map[interface{}]interface{}); ok {
+		delete(serviceData, "build")
+		if context, ok := build["context"]; ok {
+			serviceData["build"] = context
+		}
+		if dockerfile, ok := build["dockerfile"]; ok {
+			serviceData["dockerfile"] = dockerfile
+		}
+		if _, ok := build["args"]; ok {
+			logrus.Warnf("Service %s: build args are not supported by version 1 files, ignoring them", name)
+		}
+	}
+
+	if logging, ok := serviceData["logging"].(map[interface{}]interface{}); ok {
+		delete(serviceData, "logging")
+		if driver, ok := logging["driver"]; ok {
+			serviceData["log_driver"] = driver
+		}
+		if options, ok := logging["options"]; ok {
+			serviceData["log_opt"] = options
+		}
+		renames["logging"] = "log_driver"
+	}
+
+	if networkMode, ok := serviceData["network_mode"]; ok {
+		serviceData["net"] = networkMode
+		delete(serviceData, "network_mode")
+		renames["network_mode"] = "net"
+	}
+
+	keys := yamlKeys(ServiceConfigV1{})
+	var unsupported []string
+	for key := range serviceData {
+		if !keys[key] && key != "extends" && !IsExtension(key) {
+			unsupported = append(unsupported, key)
+			delete(serviceData, key)
+			delete(sources, key)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		logrus.Warnf("Service %s: %s not supported by version 1 files, ignoring them", name, strings.Join(unsupported, ", "))
+	}
+
+	return serviceData, renameSources(sources, renames)
+}
+
+// renameSources returns the sources of the keys of a converted service. The
+// sources of the keys renamed to the same key are appended in the order of
+// the keys.
+func renameSources(sources map[string][]Position, renames map[string]string) map[string][]Position {
+	var keys []string
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	renamed := map[string][]Position{}
+	for _, key := range keys {
+		name := key
+		if rename, ok := renames[key]; ok {
+			name = rename
+		}
+		renamed[name] = append(renamed[name], sources[key]...)
+	}
+	return renamed
+}
+
+// yamlKeys returns the keys of the yaml tags of the fields of a struct.
+func yamlKeys(v interface{}) map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if key != "" && key != "-" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// reportExtends logs which keys a service inherits from the service it
+// extends, overrides and adds: once per level of a chain of extends.
+func reportExtends(link, base extendsLink, baseService, serviceData RawService) {
+	if logrus.GetLevel() < logrus.DebugLevel {
+		return
+	}
+
+	var inherited, overridden, added []string
+	for key := range baseService {
+		if _, ok := serviceData[key]; !ok && key != "extends" {
+			inherited = append(inherited, key)
+		}
+	}
+	for key := range serviceData {
+		if key == "extends" {
+			continue
+		}
+		if _, ok := baseService[key]; ok {
+			overridden = append(overridden, key)
+		} else {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(inherited)
+	sort.Strings(overridden)
+	sort.Strings(added)
+
+	logrus.Debugf("Service %s extends %s: inherits [%s], overrides [%s], adds [%s]", link, base, strings.Join(inherited, " "), strings.Join(overridden, " "), strings.Join(added, " "))
+}
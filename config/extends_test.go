@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendsCycle(t *testing.T) {
+	lookup := filesLookup{
+		"docker-compose.yml": `version: '2'
+services:
+  web:
+    extends:
+      file: base.yml
+      service: base
+`,
+		"base.yml": `version: '2'
+services:
+  base:
+    extends:
+      file: docker-compose.yml
+      service: web
+`,
+	}
+
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    image: nginx
+    extends:
+      service: web
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "docker-compose.yml:5:5: Circular reference: web in docker-compose.yml extends web in docker-compose.yml", err.Error())
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`web:
+  extends:
+    service: worker
+worker:
+  extends:
+    service: web
+`), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Circular reference: ")
+	assert.Regexp(t, "(web|worker) in docker-compose.yml extends (web|worker) in docker-compose.yml extends (web|worker) in docker-compose.yml", err.Error())
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(lookup["docker-compose.yml"]), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "base.yml:4:5: Circular reference: web in docker-compose.yml extends base in base.yml extends web in docker-compose.yml (extended by service 'web' at docker-compose.yml:4:5)", err.Error())
+}
+
+func TestExtendsErrorInExtendedFile(t *testing.T) {
+	lookup := filesLookup{
+		"base.yml": `version: '2'
+services:
+  base:
+    extends:
+      service: missing
+`,
+	}
+
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    extends:
+      file: base.yml
+      service: base
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "base.yml:5:7: Failed to find service missing to extend (extended by service 'web' at docker-compose.yml:4:5)", err.Error())
+}
+
+func TestExtendsAcrossVersions(t *testing.T) {
+	lookup := filesLookup{
+		"v1.yml": `base:
+  build: .
+  dockerfile: Dockerfile.dev
+  log_driver: syslog
+  log_opt:
+    tag: base
+  net: host
+  environment:
+    - A=1
+`,
+		"v2.yml": `version: '2.1'
+services:
+  base:
+    build:
+      context: .
+      dockerfile: Dockerfile.dev
+    logging:
+      driver: syslog
+    network_mode: host
+    networks:
+      - back
+    ports:
+      - target: 80
+        published: 8080
+`,
+	}
+
+	_, configs, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    extends:
+      file: v1.yml
+      service: base
+    environment:
+      - B=2
+`), nil)
+	assert.Nil(t, err)
+	web := configs["web"]
+	assert.Equal(t, ".", web.Build.Context)
+	assert.Equal(t, "Dockerfile.dev", web.Build.Dockerfile)
+	assert.Equal(t, Log{Driver: "syslog", Options: map[string]string{"tag": "base"}}, web.Logging)
+	assert.Equal(t, "host", web.NetworkMode)
+	assert.Equal(t, []string{"A=1", "B=2"}, []string(web.Environment))
+	assert.Equal(t, []Position{{"v1.yml", 2, 3}, {"v1.yml", 3, 3}}, web.Sources["build"])
+
+	_, configs, _, _, err = Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`web:
+  extends:
+    file: v2.yml
+    service: base
+`), nil)
+	assert.Nil(t, err)
+	web = configs["web"]
+	assert.Equal(t, ".", web.Build.Context)
+	assert.Equal(t, "Dockerfile.dev", web.Build.Dockerfile)
+	assert.Equal(t, "syslog", web.Logging.Driver)
+	assert.Equal(t, "host", web.NetworkMode)
+	assert.Equal(t, []string{"8080:80"}, web.Ports)
+	assert.Nil(t, web.Networks)
+}
+
+func TestExtendsAcrossFiles(t *testing.T) {
+	lookup := filesLookup{
+		"base.yml": `version: '2.1'
+services:
+  base:
+    build:
+      context: ./src
+      dockerfile: Dockerfile.dev
+    environment:
+      - A=1
+`,
+	}
+
+	_, configs, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    extends:
+      file: base.yml
+      service: base
+    environment:
+      - B=2
+`), nil)
+	assert.Nil(t, err)
+	web := configs["web"]
+	assert.Equal(t, "src", web.Build.Context)
+	assert.Equal(t, "Dockerfile.dev", web.Build.Dockerfile)
+	assert.Equal(t, []string{"A=1", "B=2"}, []string(web.Environment))
+}
+
+func TestExtendsReport(t *testing.T) {
+	lookup := filesLookup{
+		"base.yml": `version: '2'
+services:
+  base:
+    image: nginx
+    ports:
+      - 80
+`,
+		"common.yml": `version: '2'
+services:
+  common:
+    extends:
+      file: base.yml
+      service: base
+    ports:
+      - 443
+    restart: always
+`,
+	}
+
+	var output bytes.Buffer
+	level := logrus.GetLevel()
+	logrus.SetOutput(&output)
+	logrus.SetLevel(logrus.DebugLevel)
+	defer func() {
+		logrus.SetLevel(level)
+		logrus.SetOutput(os.Stderr)
+	}()
+
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    extends:
+      file: common.yml
+      service: common
+    command: nginx -g 'daemon off;'
+`), nil)
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "Service common in common.yml extends base in base.yml: inherits [image], overrides [ports], adds [restart]")
+	assert.Contains(t, output.String(), "Service web in docker-compose.yml extends common in common.yml: inherits [image ports restart], overrides [], adds [command]")
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"strings"
+
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+	"github.com/docker/libcompose/utils"
+)
+
+// IsExtension returns whether the specified key is an extension ("x-…") key.
+// Extension keys are ignored by libcompose and kept for the tools built on it.
+func IsExtension(key string) bool {
+	return strings.HasPrefix(key, "x-")
+}
+
+// extensions returns the extension keys of the specified map, nil if there
+// are none.
+func extensions(data map[string]interface{}) map[string]interface{} {
+	var extensions map[string]interface{}
+	for key, value := range data {
+		if IsExtension(key) {
+			if extensions == nil {
+				extensions = map[string]interface{}{}
+			}
+			extensions[key] = value
+		}
+	}
+	return extensions
+}
+
+// ParseConfig parses the top level configuration of a compose file, keeping
+// its extension keys in Extensions.
+func ParseConfig(bytes []byte) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(bytes, &config); err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(bytes, &data); err != nil {
+		return nil, err
+	}
+	config.Extensions = extensions(data)
+
+	return &config, nil
+}
+
+// Extension converts the value of an extension key into out, which is
+// typically a pointer to a struct with yaml tags. It returns false if the
+// key is not set.
+func Extension(extensions map[string]interface{}, key string, out interface{}) (bool, error) {
+	value, ok := extensions[key]
+	if !ok {
+		return false, nil
+	}
+	return true, utils.Convert(value, out)
+}
+
+// resolveMergeKeys merges the maps referenced by the "<<" keys of the services,
+// so that the anchors defined in extension blocks can be used in services:
+//
+//	x-defaults: &defaults
+//	  restart: always
+//	web:
+//	  <<: *defaults
+//	  image: nginx
+//
+// Keys set in the map itself take precedence over the merged ones.
+func resolveMergeKeys(datas RawServiceMap) {
+	for _, data := range datas {
+		for key, value := range data {
+			data[key] = resolveMergeKeysInValue(value)
+		}
+		for key, value := range mergedKeys(data["<<"]) {
+			if _, ok := data[key.(string)]; !ok {
+				data[key.(string)] = value
+			}
+		}
+		delete(data, "<<")
+	}
+}
+
+func resolveMergeKeysInValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		data := map[interface{}]interface{}{}
+		for key, value := range typedValue {
+			data[key] = resolveMergeKeysInValue(value)
+		}
+		for key, value := range mergedKeys(data["<<"]) {
+			if _, ok := data[key]; !ok {
+				data[key] = value
+			}
+		}
+		delete(data, "<<")
+		return data
+	case []interface{}:
+		list := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			list[i] = resolveMergeKeysInValue(item)
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// mergedKeys returns the keys of the map, or the list of maps, referenced by
+// a "<<" key. The first maps of a list take precedence.
+func mergedKeys(merged interface{}) map[interface{}]interface{} {
+	sources := []interface{}{merged}
+	if list, ok := merged.([]interface{}); ok {
+		sources = list
+	}
+
+	keys := map[interface{}]interface{}{}
+	for i := len(sources) - 1; i >= 0; i-- {
+		if source, ok := resolveMergeKeysInValue(sources[i]).(map[interface{}]interface{}); ok {
+			for key, value := range source {
+				keys[key] = value
+			}
+		}
+	}
+	return keys
+}
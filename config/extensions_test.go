@@ -0,0 +1,121 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceExtensions(t *testing.T) {
+	_, configV1, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+x-defaults: &defaults
+  image: nginx
+  restart: always
+web:
+  <<: *defaults
+  x-monitoring:
+    port: 9113
+`), nil)
+	assert.Nil(t, err)
+
+	_, configV2, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '2'
+x-defaults: &defaults
+  image: nginx
+  restart: always
+services:
+  web:
+    <<: *defaults
+    x-monitoring:
+      port: 9113
+`), nil)
+	assert.Nil(t, err)
+
+	for _, config := range []map[string]*ServiceConfig{configV1, configV2} {
+		assert.Len(t, config, 1)
+
+		web := config["web"]
+		assert.Equal(t, "nginx", web.Image)
+		assert.Equal(t, "always", web.Restart)
+
+		var monitoring struct {
+			Port int `yaml:"port"`
+		}
+		ok, err := Extension(web.Extensions, "x-monitoring", &monitoring)
+		assert.True(t, ok)
+		assert.Nil(t, err)
+		assert.Equal(t, 9113, monitoring.Port)
+
+		ok, err = Extension(web.Extensions, "x-logging", &monitoring)
+		assert.False(t, ok)
+		assert.Nil(t, err)
+	}
+}
+
+func TestMergeKeysPrecedence(t *testing.T) {
+	_, config, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '2'
+x-base: &base
+  image: busybox
+  labels:
+    tier: back
+x-web: &web
+  image: nginx
+services:
+  web:
+    <<: [*web, *base]
+    restart: always
+    labels:
+      <<: {tier: front, team: web}
+      tier: edge
+`), nil)
+	assert.Nil(t, err)
+
+	web := config["web"]
+	assert.Equal(t, "nginx", web.Image)
+	assert.Equal(t, "always", web.Restart)
+	assert.Equal(t, "edge", web.Labels["tier"])
+	assert.Equal(t, "web", web.Labels["team"])
+}
+
+func TestExtensionsMergedAcrossFiles(t *testing.T) {
+	configs := NewServiceConfigs()
+	_, config, _, _, err := Merge(configs, nil, &NullLookup{}, "", []byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+    x-owner: web-team
+    x-monitoring: true
+`), nil)
+	assert.Nil(t, err)
+	configs.Add("web", config["web"])
+
+	_, config, _, _, err = Merge(configs, nil, &NullLookup{}, "", []byte(`
+version: '2'
+services:
+  web:
+    x-owner: platform-team
+`), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"x-owner":      "platform-team",
+		"x-monitoring": true,
+	}, config["web"].Extensions)
+}
+
+func TestParseConfigExtensions(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+version: '2'
+x-kompose:
+  namespace: staging
+services:
+  web:
+    image: nginx
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "2", config.Version)
+	assert.Equal(t, map[string]interface{}{
+		"x-kompose": map[interface{}]interface{}{"namespace": "staging"},
+	}, config.Extensions)
+}
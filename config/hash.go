@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -33,6 +34,11 @@ func GetServiceHash(name string, config *ServiceConfig) string {
 		valueField := val.Field(i)
 		keyField := val.Type().Field(i)
 
+		// moving a value in the files does not change the service
+		if keyField.Name == "Sources" {
+			continue
+		}
+
 		serviceKeys = append(serviceKeys, keyField.Name)
 		unsortedKeyValue[keyField.Name] = valueField.Interface()
 	}
@@ -86,6 +92,10 @@ func GetServiceHash(name string, config *ServiceConfig) string {
 			for _, sliceKey := range sliceKeys {
 				io.WriteString(hash, fmt.Sprintf("%s, ", sliceKey))
 			}
+		case DeployConfig, []ServiceFile:
+			// %v would write the addresses of their pointer fields
+			data, _ := json.Marshal(s)
+			io.WriteString(hash, string(data))
 		default:
 			io.WriteString(hash, fmt.Sprintf("%v", serviceValue))
 		}
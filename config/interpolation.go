@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,7 +20,10 @@ func validVariableNameChar(c uint8) bool {
 		isNum(c)
 }
 
-func parseVariable(line string, pos int, mapping func(string) string) (string, int, bool) {
+// errInvalidInterpolation is returned for lines which are not valid interpolation expressions.
+var errInvalidInterpolation = errors.New("invalid interpolation format")
+
+func parseVariable(line string, pos int, mapping func(string) (string, bool)) (string, int, error) {
 	var buffer bytes.Buffer
 
 	for ; pos < len(line); pos++ {
@@ -29,14 +33,27 @@ func parseVariable(line string, pos int, mapping func(string) string) (string, i
 		case validVariableNameChar(c):
 			buffer.WriteByte(c)
 		default:
-			return mapping(buffer.String()), pos - 1, true
+			return lookupVariable(buffer.String(), mapping), pos - 1, nil
 		}
 	}
 
-	return mapping(buffer.String()), pos, true
+	return lookupVariable(buffer.String(), mapping), pos, nil
+}
+
+// lookupVariable returns the value of a variable, substituting a blank
+// string if it is not set.
+func lookupVariable(name string, mapping func(string) (string, bool)) string {
+	value, set := mapping(name)
+	if !set {
+		logrus.Warnf("The %s variable is not set. Substituting a blank string.", name)
+	}
+	return value
 }
 
-func parseVariableWithBraces(line string, pos int, mapping func(string) string) (string, int, bool) {
+// parseVariableWithBraces parses ${VAR}, ${VAR:-default}, ${VAR-default},
+// ${VAR:?error} and ${VAR?error}. Like in shells, the forms with a colon
+// apply to unset and empty variables, the others only to unset variables.
+func parseVariableWithBraces(line string, pos int, mapping func(string) (string, bool)) (string, int, error) {
 	var buffer bytes.Buffer
 
 	for ; pos < len(line); pos++ {
@@ -47,37 +64,76 @@ func parseVariableWithBraces(line string, pos int, mapping func(string) string)
 			bufferString := buffer.String()
 
 			if bufferString == "" {
-				return "", 0, false
+				return "", 0, errInvalidInterpolation
 			}
 
-			return mapping(buffer.String()), pos, true
+			return lookupVariable(bufferString, mapping), pos, nil
 		case validVariableNameChar(c):
 			buffer.WriteByte(c)
+		case (c == ':' || c == '-' || c == '?') && buffer.Len() > 0:
+			return parseVariableModifier(line, pos, buffer.String(), mapping)
 		default:
-			return "", 0, false
+			return "", 0, errInvalidInterpolation
 		}
 	}
 
-	return "", 0, false
+	return "", 0, errInvalidInterpolation
 }
 
-func parseInterpolationExpression(line string, pos int, mapping func(string) string) (string, int, bool) {
+// parseVariableModifier parses the default value or error message following
+// the name of a variable, up to the closing brace.
+func parseVariableModifier(line string, pos int, name string, mapping func(string) (string, bool)) (string, int, error) {
+	emptyIsUnset := line[pos] == ':'
+	if emptyIsUnset {
+		pos++
+	}
+	if pos >= len(line) || (line[pos] != '-' && line[pos] != '?') {
+		return "", 0, errInvalidInterpolation
+	}
+	required := line[pos] == '?'
+
+	end := strings.IndexByte(line[pos+1:], '}')
+	if end < 0 {
+		return "", 0, errInvalidInterpolation
+	}
+	word := line[pos+1 : pos+1+end]
+	pos += end + 1
+
+	value, set := mapping(name)
+	if set && (value != "" || !emptyIsUnset) {
+		return value, pos, nil
+	}
+
+	if !required {
+		return word, pos, nil
+	}
+	if word == "" {
+		if set {
+			word = fmt.Sprintf("%s is empty", name)
+		} else {
+			word = fmt.Sprintf("%s is not set", name)
+		}
+	}
+	return "", 0, errors.New(word)
+}
+
+func parseInterpolationExpression(line string, pos int, mapping func(string) (string, bool)) (string, int, error) {
 	c := line[pos]
 
 	switch {
 	case c == '$':
-		return "$", pos, true
+		return "$", pos, nil
 	case c == '{':
 		return parseVariableWithBraces(line, pos+1, mapping)
 	case !isNum(c) && validVariableNameChar(c):
 		// Variables can't start with a number
 		return parseVariable(line, pos, mapping)
 	default:
-		return "", 0, false
+		return "", 0, errInvalidInterpolation
 	}
 }
 
-func parseLine(line string, mapping func(string) string) (string, bool) {
+func parseLine(line string, mapping func(string) (string, bool)) (string, error) {
 	var buffer bytes.Buffer
 
 	for pos := 0; pos < len(line); pos++ {
@@ -85,12 +141,12 @@ func parseLine(line string, mapping func(string) string) (string, bool) {
 		switch {
 		case c == '$':
 			var replaced string
-			var success bool
+			var err error
 
-			replaced, pos, success = parseInterpolationExpression(line, pos+1, mapping)
+			replaced, pos, err = parseInterpolationExpression(line, pos+1, mapping)
 
-			if !success {
-				return "", false
+			if err != nil {
+				return "", err
 			}
 
 			buffer.WriteString(replaced)
@@ -99,19 +155,22 @@ func parseLine(line string, mapping func(string) string) (string, bool) {
 		}
 	}
 
-	return buffer.String(), true
+	return buffer.String(), nil
 }
 
-func parseConfig(option, service string, data *interface{}, mapping func(string) string) error {
+func parseConfig(option, service string, data *interface{}, mapping func(string) (string, bool)) error {
 	switch typedData := (*data).(type) {
 	case string:
-		var success bool
+		var err error
 
-		*data, success = parseLine(typedData, mapping)
+		*data, err = parseLine(typedData, mapping)
 
-		if !success {
+		if err == errInvalidInterpolation {
 			return fmt.Errorf("Invalid interpolation format for \"%s\" option in service \"%s\": \"%s\"", option, service, typedData)
 		}
+		if err != nil {
+			return fmt.Errorf("Missing mandatory value for \"%s\" option in service \"%s\": %v", option, service, err)
+		}
 	case []interface{}:
 		for k, v := range typedData {
 			err := parseConfig(option, service, &v, mapping)
@@ -141,12 +200,11 @@ func parseConfig(option, service string, data *interface{}, mapping func(string)
 func Interpolate(environmentLookup EnvironmentLookup, config *RawServiceMap) error {
 	for k, v := range *config {
 		for k2, v2 := range v {
-			err := parseConfig(k2, k, &v2, func(s string) string {
+			err := parseConfig(k2, k, &v2, func(s string) (string, bool) {
 				values := environmentLookup.Lookup(s, k, nil)
 
 				if len(values) == 0 {
-					logrus.Warnf("The %s variable is not set. Substituting a blank string.", s)
-					return ""
+					return "", false
 				}
 
 				// Use first result if many are given
@@ -154,11 +212,11 @@ func Interpolate(environmentLookup EnvironmentLookup, config *RawServiceMap) err
 
 				// Environment variables come in key=value format
 				// Return everything past first '='
-				return strings.SplitN(value, "=", 2)[1]
+				return strings.SplitN(value, "=", 2)[1], true
 			})
 
 			if err != nil {
-				return err
+				return &interpolationError{service: k, option: k2, err: err}
 			}
 
 			(*config)[k][k2] = v2
@@ -167,3 +225,14 @@ func Interpolate(environmentLookup EnvironmentLookup, config *RawServiceMap) err
 
 	return nil
 }
+
+// interpolationError is an error interpolating an option of a service.
+type interpolationError struct {
+	service string
+	option  string
+	err     error
+}
+
+func (e *interpolationError) Error() string {
+	return e.err.Error()
+}
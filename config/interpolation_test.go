@@ -10,19 +10,20 @@ import (
 )
 
 func testInterpolatedLine(t *testing.T, expectedLine, interpolatedLine string, envVariables map[string]string) {
-	interpolatedLine, _ = parseLine(interpolatedLine, func(s string) string {
-		return envVariables[s]
+	interpolatedLine, _ = parseLine(interpolatedLine, func(s string) (string, bool) {
+		value, ok := envVariables[s]
+		return value, ok
 	})
 
 	assert.Equal(t, expectedLine, interpolatedLine)
 }
 
 func testInvalidInterpolatedLine(t *testing.T, line string) {
-	_, success := parseLine(line, func(string) string {
-		return ""
+	_, err := parseLine(line, func(string) (string, bool) {
+		return "", false
 	})
 
-	assert.Equal(t, false, success)
+	assert.Equal(t, errInvalidInterpolation, err)
 }
 
 func TestParseLine(t *testing.T) {
@@ -77,6 +78,47 @@ func TestParseLine(t *testing.T) {
 	testInvalidInterpolatedLine(t, "$!")
 }
 
+func TestParseLineDefaultsAndRequired(t *testing.T) {
+	variables := map[string]string{
+		"A": "ABC",
+		"E": "",
+	}
+
+	testInterpolatedLine(t, "ABC", "${A:-default}", variables)
+	testInterpolatedLine(t, "ABC", "${A-default}", variables)
+	testInterpolatedLine(t, "default", "${E:-default}", variables)
+	testInterpolatedLine(t, "", "${E-default}", variables)
+	testInterpolatedLine(t, "default", "${B:-default}", variables)
+	testInterpolatedLine(t, "default", "${B-default}", variables)
+	testInterpolatedLine(t, "", "${B:-}", variables)
+	testInterpolatedLine(t, "a default:with-chars", "${B:-a default:with-chars}", variables)
+	testInterpolatedLine(t, "ABC-8080", "${A}-${PORT:-8080}", variables)
+
+	testInterpolatedLine(t, "ABC", "${A:?error}", variables)
+	testInterpolatedLine(t, "", "${E?error}", variables)
+
+	for line, message := range map[string]string{
+		"${E:?error}":  "error",
+		"${B:?error}":  "error",
+		"${B?error}":   "error",
+		"${B?}":        "B is not set",
+		"${E:?}":       "E is empty",
+		"x${B:?a b c}": "a b c",
+	} {
+		_, err := parseLine(line, func(s string) (string, bool) {
+			value, ok := variables[s]
+			return value, ok
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, message, err.Error())
+	}
+
+	testInvalidInterpolatedLine(t, "${A:}")
+	testInvalidInterpolatedLine(t, "${A:x}")
+	testInvalidInterpolatedLine(t, "${:-default}")
+	testInvalidInterpolatedLine(t, "${A:-default")
+}
+
 type MockEnvironmentLookup struct {
 	Variables map[string]string
 }
@@ -118,6 +160,23 @@ func testInvalidInterpolatedConfig(t *testing.T, interpolatedConfig string) {
 	assert.NotNil(t, err)
 }
 
+func TestInterpolateRequired(t *testing.T) {
+	interpolatedData := make(RawServiceMap)
+	yaml.Unmarshal([]byte(`web:
+  image: "${IMAGE:?set IMAGE to the image to run}"`), &interpolatedData)
+
+	err := Interpolate(emptyEnvironmentLookup{}, &interpolatedData)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, `Missing mandatory value for "image" option in service "web": set IMAGE to the image to run`, err.Error())
+}
+
+type emptyEnvironmentLookup struct{}
+
+func (e emptyEnvironmentLookup) Lookup(key, serviceName string, config *ServiceConfig) []string {
+	return nil
+}
+
 func TestInterpolate(t *testing.T) {
 	testInterpolatedConfig(t,
 		`web:
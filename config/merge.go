@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"path"
 	"strings"
 
 	yaml "github.com/cloudfoundry-incubator/candiedyaml"
@@ -32,11 +33,15 @@ func Merge(existingServices *ServiceConfigs, environmentLookup EnvironmentLookup
 		return "", nil, nil, nil, err
 	}
 
+	version, err := schemaVersion(config.Version)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
 	var serviceConfigs map[string]*ServiceConfig
 	var volumeConfigs map[string]*VolumeConfig
 	var networkConfigs map[string]*NetworkConfig
-	if config.Version == "2" {
-		var err error
+	if version != "1" {
 		serviceConfigs, err = MergeServicesV2(existingServices, environmentLookup, resourceLookup, file, bytes, options)
 		if err != nil {
 			return "", nil, nil, nil, err
@@ -141,7 +146,16 @@ func readEnvFile(resourceLookup ResourceLookup, inFile string, serviceData RawSe
 	return serviceData, nil
 }
 
-func mergeConfig(baseService, serviceData RawService) RawService {
+// mergeConfig merges a service into the service it overrides, or extends,
+// with the merge strategies of its keys. The keys tagged !reset remove the
+// values of the base service, the ones tagged !override replace them.
+func mergeConfig(baseService, serviceData RawService, tags map[string]string) RawService {
+	for k, tag := range tags {
+		if tag == resetTag {
+			delete(baseService, k)
+		}
+	}
+
 	for k, v := range serviceData {
 		// Image and build are mutually exclusive in merge
 		if k == "image" {
@@ -150,8 +164,8 @@ func mergeConfig(baseService, serviceData RawService) RawService {
 			delete(baseService, "image")
 		}
 		existing, ok := baseService[k]
-		if ok {
-			baseService[k] = merge(existing, v)
+		if ok && tags[k] != overrideTag {
+			baseService[k] = mergeValue(k, existing, v)
 		} else {
 			baseService[k] = v
 		}
@@ -160,6 +174,66 @@ func mergeConfig(baseService, serviceData RawService) RawService {
 	return baseService
 }
 
+// resolveContext returns the build context of a service of the file inFile.
+// The relative contexts of local files resolve against their directory, and
+// the ones of the files of git repositories against their directory in the
+// repository, like the sources of docker builds: <repository>#<ref>:<path>.
+// The relative contexts of the other remote files can not be built.
+func resolveContext(context, inFile string) (string, error) {
+	if IsValidRemote(context) {
+		return context, nil
+	}
+
+	if repository, fragment, ok := splitGitFile(inFile); ok {
+		if path.IsAbs(context) {
+			return context, nil
+		}
+		parts := strings.SplitN(fragment, ":", 2)
+		file := ""
+		if len(parts) == 2 {
+			file = parts[1]
+		}
+		dir := strings.TrimPrefix(path.Join(path.Dir(path.Clean("/"+file)), context), "/")
+		if dir == "" {
+			if parts[0] == "" {
+				return repository, nil
+			}
+			return repository + "#" + parts[0], nil
+		}
+		return repository + "#" + parts[0] + ":" + dir, nil
+	}
+
+	if strings.Contains(inFile, "://") {
+		if path.IsAbs(context) {
+			return context, nil
+		}
+		return "", fmt.Errorf("Build context %s of %s can not be resolved: only the relative contexts of the files of git repositories can be built", context, inFile)
+	}
+
+	return path.Join(path.Dir(inFile), context), nil
+}
+
+// splitGitFile splits a file of a git repository, named like
+// <repository>#<ref>:<path>, into its repository and fragment.
+func splitGitFile(file string) (string, string, bool) {
+	parts := strings.SplitN(file, "#", 2)
+	repository := parts[0]
+
+	isGit := strings.HasPrefix(repository, "git://") || strings.HasPrefix(repository, "git@")
+	for _, scheme := range []string{"http://", "https://", "ssh://", "file://"} {
+		if strings.HasPrefix(repository, scheme) && strings.HasSuffix(repository, ".git") {
+			isGit = true
+		}
+	}
+	if !isGit {
+		return "", "", false
+	}
+	if len(parts) == 1 {
+		return repository, "", true
+	}
+	return repository, parts[1], true
+}
+
 // IsValidRemote checks if the specified string is a valid remote (for builds)
 func IsValidRemote(remote string) bool {
 	return urlutil.IsGitURL(remote) || urlutil.IsURL(remote)
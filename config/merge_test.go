@@ -291,6 +291,31 @@ func TestIsValidRemote(t *testing.T) {
 	}
 }
 
+func TestResolveContext(t *testing.T) {
+	valids := map[[2]string]string{
+		{".", "docker-compose.yml"}:                                              ".",
+		{"./app", "compose/docker-compose.yml"}:                                  "compose/app",
+		{"https://example.com/app.git", "compose/docker-compose.yml"}:            "https://example.com/app.git",
+		{".", "git://example.com/repo.git"}:                                      "git://example.com/repo.git",
+		{"app", "git://example.com/repo.git"}:                                    "git://example.com/repo.git#:app",
+		{".", "git://example.com/repo.git#v1:compose/docker-compose.yml"}:        "git://example.com/repo.git#v1:compose",
+		{"../app", "git@example.com:org/repo.git#v1:compose/docker-compose.yml"}: "git@example.com:org/repo.git#v1:app",
+		{"..", "https://example.com/repo.git#v1:compose/docker-compose.yml"}:     "https://example.com/repo.git#v1",
+		{"/src/app", "https://example.com/compose/docker-compose.yml"}:           "/src/app",
+	}
+	for valid, expected := range valids {
+		if context, err := resolveContext(valid[0], valid[1]); err != nil || context != expected {
+			t.Fatalf("Expected the context %s of %s to resolve to %s, got %s, %v", valid[0], valid[1], expected, context, err)
+		}
+	}
+
+	_, err := resolveContext("./app", "https://example.com/compose/docker-compose.yml")
+	expectedError := "Build context ./app of https://example.com/compose/docker-compose.yml can not be resolved: only the relative contexts of the files of git repositories can be built"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error with '%s', got '%v'", expectedError, err)
+	}
+}
+
 func preprocess(services RawServiceMap) (RawServiceMap, error) {
 	for name := range services {
 		services[name]["image"] = "foo2"
@@ -353,3 +378,45 @@ services:
 		}
 	}
 }
+
+func TestKomposeExtensions(t *testing.T) {
+	_, configV1, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+web:
+  image: nginx
+proxy:
+  image: haproxy
+  x-kompose-sidecar-of: web
+migrate:
+  image: flyway
+  x-kompose-init-of: web
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, configV2, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+  proxy:
+    image: haproxy
+    x-kompose-sidecar-of: web
+  migrate:
+    image: flyway
+    x-kompose-init-of: web
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, config := range []map[string]*ServiceConfig{configV1, configV2} {
+		if config["proxy"].Extensions["x-kompose-sidecar-of"] != "web" {
+			t.Fatal("Invalid sidecar", config["proxy"].Extensions)
+		}
+
+		if config["migrate"].Extensions["x-kompose-init-of"] != "web" {
+			t.Fatal("Invalid init container", config["migrate"].Extensions)
+		}
+	}
+}
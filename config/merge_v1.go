@@ -1,8 +1,8 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"path"
 
 	"github.com/Sirupsen/logrus"
 	yaml "github.com/cloudfoundry-incubator/candiedyaml"
@@ -11,14 +11,18 @@ import (
 
 // MergeServicesV1 merges a v1 compose file into an existing set of service configs
 func MergeServicesV1(existingServices *ServiceConfigs, environmentLookup EnvironmentLookup, resourceLookup ResourceLookup, file string, bytes []byte, options *ParseOptions) (map[string]*ServiceConfigV1, error) {
-	datas := make(RawServiceMap)
-	if err := yaml.Unmarshal(bytes, &datas); err != nil {
+	l := newLocator(file, bytes)
+
+	datas, err := servicesV1(bytes, l)
+	if err != nil {
 		return nil, err
 	}
+	resolveMergeKeys(datas)
+	l.reset(datas)
 
 	if options.Interpolate {
 		if err := Interpolate(environmentLookup, &datas); err != nil {
-			return nil, err
+			return nil, l.wrap(err)
 		}
 	}
 
@@ -31,33 +35,40 @@ func MergeServicesV1(existingServices *ServiceConfigs, environmentLookup Environ
 	}
 
 	if options.Validate {
-		if err := validate(datas); err != nil {
+		if err := validate(datas, l); err != nil {
 			return nil, err
 		}
 	}
 
 	for name, data := range datas {
-		data, err := parseV1(resourceLookup, environmentLookup, file, data, datas, options)
+		data, sources, err := parseV1(resourceLookup, environmentLookup, file, l, name, data, datas, options, nil)
 		if err != nil {
 			logrus.Errorf("Failed to parse service %s: %v", name, err)
 			return nil, err
 		}
 
 		if serviceConfig, ok := existingServices.Get(name); ok {
+			tags := l.serviceTags(name)
+			sources = mergeSources(serviceConfig.Sources, sources, tags)
+
 			var rawExistingService RawService
 			if err := utils.Convert(serviceConfig, &rawExistingService); err != nil {
 				return nil, err
 			}
+			for key, value := range serviceConfig.Extensions {
+				rawExistingService[key] = value
+			}
 
-			data = mergeConfig(rawExistingService, data)
+			data = mergeConfig(rawExistingService, data, tags)
 		}
 
 		datas[name] = data
+		l.sources[name] = sources
 	}
 
 	if options.Validate {
 		for name, data := range datas {
-			err := validateServiceConstraints(data, name)
+			err := validateServiceConstraints(data, name, l)
 			if err != nil {
 				return nil, err
 			}
@@ -68,132 +79,70 @@ func MergeServicesV1(existingServices *ServiceConfigs, environmentLookup Environ
 	if err := utils.Convert(datas, &serviceConfigs); err != nil {
 		return nil, err
 	}
+	for name, serviceConfig := range serviceConfigs {
+		serviceConfig.Extensions = extensions(datas[name])
+		serviceConfig.Sources = l.sources[name]
+	}
 
 	return serviceConfigs, nil
 }
 
-func parseV1(resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, serviceData RawService, datas RawServiceMap, options *ParseOptions) (RawService, error) {
-	serviceData, err := readEnvFile(resourceLookup, inFile, serviceData)
-	if err != nil {
+// servicesV1 returns the services of a v1 compose file, located with l.
+func servicesV1(bytes []byte, l *locator) (RawServiceMap, error) {
+	var rawDatas map[string]interface{}
+	if err := yaml.Unmarshal(bytes, &rawDatas); err != nil {
 		return nil, err
 	}
 
-	serviceData = resolveContextV1(inFile, serviceData)
-
-	value, ok := serviceData["extends"]
-	if !ok {
-		return serviceData, nil
-	}
-
-	mapValue, ok := value.(map[interface{}]interface{})
-	if !ok {
-		return serviceData, nil
-	}
-
-	if resourceLookup == nil {
-		return nil, fmt.Errorf("Can not use extends in file %s no mechanism provided to files", inFile)
-	}
-
-	file := asString(mapValue["file"])
-	service := asString(mapValue["service"])
-
-	if service == "" {
-		return serviceData, nil
-	}
-
-	var baseService RawService
-
-	if file == "" {
-		if serviceData, ok := datas[service]; ok {
-			baseService, err = parseV1(resourceLookup, environmentLookup, inFile, serviceData, datas, options)
-		} else {
-			return nil, fmt.Errorf("Failed to find service %s to extend", service)
-		}
-	} else {
-		bytes, resolved, err := resourceLookup.Lookup(file, inFile)
-		if err != nil {
-			logrus.Errorf("Failed to lookup file %s: %v", file, err)
-			return nil, err
-		}
-
-		var baseRawServices RawServiceMap
-		if err := yaml.Unmarshal(bytes, &baseRawServices); err != nil {
-			return nil, err
-		}
-
-		if options.Interpolate {
-			err = Interpolate(environmentLookup, &baseRawServices)
-			if err != nil {
-				return nil, err
-			}
+	// top level extensions are not services
+	datas := make(RawServiceMap)
+	for name, rawData := range rawDatas {
+		if IsExtension(name) {
+			continue
 		}
-
-		if options.Preprocess != nil {
-			var err error
-			baseRawServices, err = options.Preprocess(baseRawServices)
-			if err != nil {
-				return nil, err
-			}
+		data, ok := rawData.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New(locate(l.at(name), fmt.Sprintf("Invalid service %s: it should be a map", name)))
 		}
-
-		if options.Validate {
-			if err := validate(baseRawServices); err != nil {
-				return nil, err
-			}
+		datas[name] = make(RawService)
+		for key, value := range data {
+			datas[name][fmt.Sprint(key)] = value
 		}
+	}
 
-		baseService, ok = baseRawServices[service]
-		if !ok {
-			return nil, fmt.Errorf("Failed to find service %s in file %s", service, file)
-		}
+	return datas, nil
+}
 
-		baseService, err = parseV1(resourceLookup, environmentLookup, resolved, baseService, baseRawServices, options)
-	}
+// parseV1 parses the service name of the file inFile, located with l. It
+// returns the service and the sources of its keys. chain holds the services
+// extending this one.
+func parseV1(resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, l *locator, name string, serviceData RawService, datas RawServiceMap, options *ParseOptions, chain []extendsLink) (RawService, map[string][]Position, error) {
+	sources := l.serviceSources(name, serviceData)
 
+	serviceData, err := readEnvFile(resourceLookup, inFile, serviceData)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	baseService = clone(baseService)
-
-	logrus.Debugf("Merging %#v, %#v", baseService, serviceData)
-
-	for _, k := range noMerge {
-		if _, ok := baseService[k]; ok {
-			source := file
-			if source == "" {
-				source = inFile
-			}
-			return nil, fmt.Errorf("Cannot extend service '%s' in %s: services with '%s' cannot be extended", service, source, k)
-		}
+	serviceData, err = resolveContextV1(inFile, serviceData)
+	if err != nil {
+		return nil, nil, errors.New(locate(l.service(name, "build"), err.Error()))
 	}
 
-	baseService = mergeConfig(baseService, serviceData)
-
-	logrus.Debugf("Merged result %#v", baseService)
-
-	return baseService, nil
+	return parseExtends("1", resourceLookup, environmentLookup, inFile, l, name, serviceData, sources, datas, options, chain)
 }
 
-func resolveContextV1(inFile string, serviceData RawService) RawService {
+func resolveContextV1(inFile string, serviceData RawService) (RawService, error) {
 	context := asString(serviceData["build"])
 	if context == "" {
-		return serviceData
-	}
-
-	if IsValidRemote(context) {
-		return serviceData
+		return serviceData, nil
 	}
 
-	current := path.Dir(inFile)
-
-	if context == "." {
-		context = current
-	} else {
-		current = path.Join(current, context)
+	context, err := resolveContext(context, inFile)
+	if err != nil {
+		return nil, err
 	}
+	serviceData["build"] = context
 
-	serviceData["build"] = current
-
-	return serviceData
+	return serviceData, nil
 }
@@ -1,26 +1,33 @@
 package config
 
 import (
-	"fmt"
-	"path"
+	"errors"
 
 	"github.com/Sirupsen/logrus"
 	yaml "github.com/cloudfoundry-incubator/candiedyaml"
 	"github.com/docker/libcompose/utils"
 )
 
-// MergeServicesV2 merges a v2 compose file into an existing set of service configs
+// MergeServicesV2 merges a 2.x or 3.x compose file into an existing set of service configs
 func MergeServicesV2(existingServices *ServiceConfigs, environmentLookup EnvironmentLookup, resourceLookup ResourceLookup, file string, bytes []byte, options *ParseOptions) (map[string]*ServiceConfig, error) {
 	var config Config
 	if err := yaml.Unmarshal(bytes, &config); err != nil {
 		return nil, err
 	}
 
+	version, err := schemaVersion(config.Version)
+	if err != nil {
+		return nil, err
+	}
+
 	datas := config.Services
+	resolveMergeKeys(datas)
+	l := newLocator(file, bytes, "services")
+	l.reset(datas)
 
 	if options.Interpolate {
 		if err := Interpolate(environmentLookup, &datas); err != nil {
-			return nil, err
+			return nil, l.wrap(err)
 		}
 	}
 
@@ -32,29 +39,62 @@ func MergeServicesV2(existingServices *ServiceConfigs, environmentLookup Environ
 		}
 	}
 
+	_, validated := versionSchemas[version]
+	if options.Validate && validated {
+		var data map[string]interface{}
+		if err := yaml.Unmarshal(bytes, &data); err != nil {
+			return nil, err
+		}
+		if err := validateVersion(version, data, datas, l); err != nil {
+			return nil, err
+		}
+	}
+
 	for name, data := range datas {
-		data, err := parseV2(resourceLookup, environmentLookup, file, data, datas, options)
+		data, sources, err := parseV2(version, resourceLookup, environmentLookup, file, l, name, data, datas, options, nil)
 		if err != nil {
 			logrus.Errorf("Failed to parse service %s: %v", name, err)
 			return nil, err
 		}
 
 		if serviceConfig, ok := existingServices.Get(name); ok {
+			tags := l.serviceTags(name)
+			sources = mergeSources(serviceConfig.Sources, sources, tags)
+
 			var rawExistingService RawService
 			if err := utils.Convert(serviceConfig, &rawExistingService); err != nil {
 				return nil, err
 			}
+			for key, value := range serviceConfig.Extensions {
+				rawExistingService[key] = value
+			}
+			if build, ok := rawExistingService["build"].(map[interface{}]interface{}); ok && len(build) == 0 {
+				delete(rawExistingService, "build")
+			}
 
-			data = mergeConfig(rawExistingService, data)
+			data = mergeConfig(rawExistingService, data, tags)
 		}
 
-		datas[name] = data
+		datas[name] = normalizeService(name, data)
+		l.sources[name] = sources
+	}
+
+	if options.Validate && validated {
+		for name, data := range datas {
+			if err := validateVersionConstraints(version, data, name, l); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	serviceConfigs := make(map[string]*ServiceConfig)
 	if err := utils.Convert(datas, &serviceConfigs); err != nil {
 		return nil, err
 	}
+	for name, serviceConfig := range serviceConfigs {
+		serviceConfig.Extensions = extensions(datas[name])
+		serviceConfig.Sources = l.sources[name]
+	}
 
 	return serviceConfigs, nil
 }
@@ -91,100 +131,28 @@ func ParseNetworks(bytes []byte) (map[string]*NetworkConfig, error) {
 	return networkConfigs, nil
 }
 
-func parseV2(resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, serviceData RawService, datas RawServiceMap, options *ParseOptions) (RawService, error) {
+// parseV2 parses the service name of the file inFile, of the specified schema
+// version, located with l. It returns the service and the sources of its
+// keys. chain holds the services extending this one.
+func parseV2(version string, resourceLookup ResourceLookup, environmentLookup EnvironmentLookup, inFile string, l *locator, name string, serviceData RawService, datas RawServiceMap, options *ParseOptions, chain []extendsLink) (RawService, map[string][]Position, error) {
+	sources := l.serviceSources(name, serviceData)
+
 	serviceData, err := readEnvFile(resourceLookup, inFile, serviceData)
 	if err != nil {
-		return nil, err
-	}
-
-	serviceData = resolveContextV2(inFile, serviceData)
-
-	value, ok := serviceData["extends"]
-	if !ok {
-		return serviceData, nil
-	}
-
-	mapValue, ok := value.(map[interface{}]interface{})
-	if !ok {
-		return serviceData, nil
-	}
-
-	if resourceLookup == nil {
-		return nil, fmt.Errorf("Can not use extends in file %s no mechanism provided to files", inFile)
-	}
-
-	file := asString(mapValue["file"])
-	service := asString(mapValue["service"])
-
-	if service == "" {
-		return serviceData, nil
-	}
-
-	var baseService RawService
-
-	if file == "" {
-		if serviceData, ok := datas[service]; ok {
-			baseService, err = parseV2(resourceLookup, environmentLookup, inFile, serviceData, datas, options)
-		} else {
-			return nil, fmt.Errorf("Failed to find service %s to extend", service)
-		}
-	} else {
-		bytes, resolved, err := resourceLookup.Lookup(file, inFile)
-		if err != nil {
-			logrus.Errorf("Failed to lookup file %s: %v", file, err)
-			return nil, err
-		}
-
-		var config Config
-		if err := yaml.Unmarshal(bytes, &config); err != nil {
-			return nil, err
-		}
-
-		baseRawServices := config.Services
-
-		if options.Interpolate {
-			err = Interpolate(environmentLookup, &baseRawServices)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		baseService, ok = baseRawServices[service]
-		if !ok {
-			return nil, fmt.Errorf("Failed to find service %s in file %s", service, file)
-		}
-
-		baseService, err = parseV2(resourceLookup, environmentLookup, resolved, baseService, baseRawServices, options)
+		return nil, nil, err
 	}
 
+	serviceData, err = resolveContextV2(inFile, serviceData)
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.New(locate(l.service(name, "build"), err.Error()))
 	}
 
-	baseService = clone(baseService)
-
-	logrus.Debugf("Merging %#v, %#v", baseService, serviceData)
-
-	for _, k := range noMerge {
-		if _, ok := baseService[k]; ok {
-			source := file
-			if source == "" {
-				source = inFile
-			}
-			return nil, fmt.Errorf("Cannot extend service '%s' in %s: services with '%s' cannot be extended", service, source, k)
-		}
-	}
-
-	baseService = mergeConfig(baseService, serviceData)
-
-	logrus.Debugf("Merged result %#v", baseService)
-
-	return baseService, nil
+	return parseExtends(version, resourceLookup, environmentLookup, inFile, l, name, serviceData, sources, datas, options, chain)
 }
 
-func resolveContextV2(inFile string, serviceData RawService) RawService {
+func resolveContextV2(inFile string, serviceData RawService) (RawService, error) {
 	if _, ok := serviceData["build"]; !ok {
-		return serviceData
+		return serviceData, nil
 	}
 	var build map[interface{}]interface{}
 	if buildAsString, ok := serviceData["build"].(string); ok {
@@ -196,22 +164,14 @@ func resolveContextV2(inFile string, serviceData RawService) RawService {
 	}
 	context := asString(build["context"])
 	if context == "" {
-		return serviceData
-	}
-
-	if IsValidRemote(context) {
-		return serviceData
+		return serviceData, nil
 	}
 
-	current := path.Dir(inFile)
-
-	if context == "." {
-		context = current
-	} else {
-		current = path.Join(current, context)
+	context, err := resolveContext(context, inFile)
+	if err != nil {
+		return nil, err
 	}
+	build["context"] = context
 
-	build["context"] = current
-
-	return serviceData
+	return serviceData, nil
 }
@@ -0,0 +1,609 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position is the position of a value in a compose file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String returns the position as "file:line:column", "line:column" if the
+// file has no name.
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// positions holds the positions of the keys and the items of the mappings
+// and sequences of a compose file, by path ("services.web.ports.0").
+type positions map[string]Position
+
+// parsePositions indexes the positions of the values of a compose file. The
+// file is expected to be valid YAML: lines it cannot make sense of are
+// skipped.
+func parsePositions(file string, data []byte) positions {
+	index, _ := scanPositions(file, data)
+	return index
+}
+
+// scanPositions indexes the positions of the values of a compose file, and
+// the merge tags (!reset and !override) of its keys, by path. The file is
+// scanned line by line, the YAML decoder not exposing the positions nor the
+// tags of the collections: the values of an alias are located at the alias,
+// and the keys merged into a mapping ("<<: *default") are not located.
+func scanPositions(file string, data []byte) (positions, map[string]string) {
+	type node struct {
+		indent int
+		path   string
+		item   bool
+	}
+
+	index := positions{}
+	tags := map[string]string{}
+	items := map[string]int{}
+	stack := []node{{indent: -1}}
+	// scalarIndent is the indentation of the key, or item, whose block or
+	// multi-line scalar goes on over the next more indented lines
+	scalarIndent := -1
+
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	for line := 0; line < len(lines); line++ {
+		text := lines[line]
+		content := strings.TrimLeft(text, " ")
+		indent := len(text) - len(content)
+		if content == "" || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "---") {
+			continue
+		}
+		if scalarIndent >= 0 {
+			if indent > scalarIndent {
+				continue
+			}
+			scalarIndent = -1
+		}
+
+		item, itemIndent := "", -1
+		for {
+			for len(stack) > 1 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+
+			if content == "-" || strings.HasPrefix(content, "- ") {
+				// the items of a sequence can be indented like its key
+				if top := stack[len(stack)-1]; top.indent == indent && top.item && len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+				parent := stack[len(stack)-1].path
+				path := joinPath(parent, strconv.Itoa(items[parent]))
+				items[parent]++
+				index[path] = Position{File: file, Line: line + 1, Column: indent + 1}
+				stack = append(stack, node{indent: indent, path: path, item: true})
+
+				rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+				if rest == "" || strings.HasPrefix(rest, "#") {
+					break
+				}
+				item, itemIndent = path, indent
+				indent += len(content) - len(rest)
+				content = rest
+				continue
+			}
+
+			if _, value := splitProperties(content); item != "" && isFlow(value) {
+				line = scanFlow(file, lines, line, len(text)-len(value), item, index, tags)
+				break
+			}
+
+			key, value, ok := splitKey(content)
+			if !ok {
+				// a scalar item, which can go on over the next lines
+				if item != "" {
+					scalarIndent = itemIndent
+				}
+				break
+			}
+			for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			path := joinPath(stack[len(stack)-1].path, key)
+			index[path] = Position{File: file, Line: line + 1, Column: indent + 1}
+			stack = append(stack, node{indent: indent, path: path})
+
+			tag, rest := splitProperties(value)
+			if tag != "" {
+				tags[path] = tag
+			}
+			switch {
+			case isFlow(rest):
+				line = scanFlow(file, lines, line, len(strings.TrimRight(text, " \t"))-len(rest), path, index, tags)
+			case rest != "" && !strings.HasPrefix(rest, "#"):
+				// block scalars ("key: |") and plain or quoted scalars
+				// go on over the next more indented lines
+				scalarIndent = indent
+			}
+			break
+		}
+	}
+	return index, tags
+}
+
+// splitKey splits the content of a line holding a mapping key ("key: value",
+// `"key": value` or "key:") into its key and value.
+func splitKey(content string) (string, string, bool) {
+	if strings.HasPrefix(content, `"`) || strings.HasPrefix(content, "'") {
+		quote := content[:1]
+		end := strings.Index(content[1:], quote)
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest := content[1:end+1], content[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+
+	if strings.HasPrefix(content, "[") || strings.HasPrefix(content, "{") {
+		return "", "", false
+	}
+	if i := strings.Index(content, ": "); i >= 0 {
+		return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+2:]), true
+	}
+	if strings.HasSuffix(content, ":") {
+		return strings.TrimSpace(strings.TrimSuffix(content, ":")), "", true
+	}
+	return "", "", false
+}
+
+// splitProperties splits the tags and the anchor ("!reset", "&default") off
+// a value, and returns its merge tag, if it has one.
+func splitProperties(value string) (string, string) {
+	tag := ""
+	for strings.HasPrefix(value, "!") || strings.HasPrefix(value, "&") {
+		end := strings.IndexAny(value, " \t")
+		if end < 0 {
+			end = len(value)
+		}
+		if property := value[:end]; property == resetTag || property == overrideTag {
+			tag = property
+		}
+		value = strings.TrimLeft(value[end:], " \t")
+	}
+	return tag, value
+}
+
+func isFlow(value string) bool {
+	return strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{")
+}
+
+// scanFlow indexes the keys, the items and the merge tags of the flow
+// collection ("[80, 443]", "{ports: !reset []}") at the specified column of a
+// line, which is the value at path. It returns the line the collection ends
+// on.
+func scanFlow(file string, lines []string, line, column int, path string, index positions, tags map[string]string) int {
+	s := &flowScanner{file: file, lines: lines, line: line, column: column, index: index, tags: tags}
+	s.node(path)
+	if s.line >= len(lines) {
+		return len(lines) - 1
+	}
+	return s.line
+}
+
+// flowScanner scans the flow collections of a compose file, which can span
+// lines, character by character.
+type flowScanner struct {
+	file   string
+	lines  []string
+	line   int
+	column int
+	index  positions
+	tags   map[string]string
+}
+
+// peek returns the character at the cursor, '\n' at the end of a line and 0
+// at the end of the file.
+func (s *flowScanner) peek() byte {
+	if s.line >= len(s.lines) {
+		return 0
+	}
+	if s.column >= len(s.lines[s.line]) {
+		return '\n'
+	}
+	return s.lines[s.line][s.column]
+}
+
+func (s *flowScanner) next() {
+	if s.line >= len(s.lines) {
+		return
+	}
+	if s.column >= len(s.lines[s.line]) {
+		s.line++
+		s.column = 0
+		return
+	}
+	s.column++
+}
+
+func (s *flowScanner) position() Position {
+	return Position{File: s.file, Line: s.line + 1, Column: s.column + 1}
+}
+
+// skipBlanks skips the blanks, the line breaks and the comments.
+func (s *flowScanner) skipBlanks() {
+	for {
+		switch s.peek() {
+		case ' ', '\t', '\n':
+			s.next()
+		case '#':
+			s.column = len(s.lines[s.line])
+		default:
+			return
+		}
+	}
+}
+
+// node scans the node at the cursor, the value at path, and returns its
+// merge tag, if it has one.
+func (s *flowScanner) node(path string) string {
+	tag := ""
+	for {
+		s.skipBlanks()
+		if c := s.peek(); c != '!' && c != '&' {
+			break
+		}
+		line, start := s.line, s.column
+		for c := s.peek(); !strings.ContainsRune(" \t\n,[]{}", rune(c)) && c != 0; c = s.peek() {
+			s.next()
+		}
+		if property := s.lines[line][start:s.column]; property == resetTag || property == overrideTag {
+			tag = property
+		}
+	}
+
+	switch s.peek() {
+	case '[':
+		s.next()
+		s.sequence(path)
+	case '{':
+		s.next()
+		s.mapping(path)
+	default:
+		s.scalar()
+	}
+	return tag
+}
+
+func (s *flowScanner) sequence(path string) {
+	for i := 0; ; i++ {
+		s.skipBlanks()
+		switch s.peek() {
+		case ']':
+			s.next()
+			return
+		case 0:
+			return
+		}
+
+		item := joinPath(path, strconv.Itoa(i))
+		s.index[item] = s.position()
+		s.node(item)
+		s.skipEntry('}')
+	}
+}
+
+func (s *flowScanner) mapping(path string) {
+	for {
+		s.skipBlanks()
+		switch s.peek() {
+		case '}':
+			s.next()
+			return
+		case 0:
+			return
+		}
+
+		position := s.position()
+		key := joinPath(path, s.scalar())
+		s.index[key] = position
+		s.skipBlanks()
+		if s.peek() == ':' {
+			s.next()
+			if tag := s.node(key); tag != "" {
+				s.tags[key] = tag
+			}
+		}
+		s.skipEntry(']')
+	}
+}
+
+// skipEntry skips the separator after an entry of a collection, and the
+// characters it cannot make sense of, like the closing character of the
+// other kind of collection.
+func (s *flowScanner) skipEntry(other byte) {
+	s.skipBlanks()
+	switch c := s.peek(); {
+	case c == ',' || c == other:
+		s.next()
+	case c != ']' && c != '}' && c != 0:
+		s.next()
+	}
+}
+
+// scalar scans the scalar at the cursor and returns its value, unquoted. Its
+// line breaks are folded into spaces.
+func (s *flowScanner) scalar() string {
+	var value bytes.Buffer
+	if quote := s.peek(); quote == '"' || quote == '\'' {
+		s.next()
+		for c := s.peek(); c != 0; c = s.peek() {
+			s.next()
+			switch {
+			case c == quote && quote == '\'' && s.peek() == '\'':
+				s.next()
+				value.WriteByte(c)
+			case c == quote:
+				return value.String()
+			case c == '\\' && quote == '"':
+				value.WriteByte(s.peek())
+				s.next()
+			case c == '\n':
+				value.WriteByte(' ')
+			default:
+				value.WriteByte(c)
+			}
+		}
+		return value.String()
+	}
+
+	last := byte(' ')
+	for c := s.peek(); c != 0 && c != ',' && c != '[' && c != ']' && c != '{' && c != '}'; c = s.peek() {
+		// "key: value", and a comment
+		if c == ':' && (s.column+1 >= len(s.lines[s.line]) || strings.ContainsRune(" \t,[]{}", rune(s.lines[s.line][s.column+1]))) {
+			break
+		}
+		if c == '#' && (last == ' ' || last == '\t' || last == '\n') {
+			s.column = len(s.lines[s.line])
+			continue
+		}
+		if c == '\n' {
+			value.WriteByte(' ')
+		} else {
+			value.WriteByte(c)
+		}
+		last = c
+		s.next()
+	}
+	return strings.TrimSpace(value.String())
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// find returns the position of the value at the specified path, or of its
+// closest parent whose position is known.
+func (p positions) find(keys ...string) (Position, bool) {
+	for i := len(keys); i > 0; i-- {
+		if position, ok := p[strings.Join(keys[:i], ".")]; ok {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+// locator locates the values of a compose file, and the values of the
+// services parsed from it, in the errors about them.
+type locator struct {
+	positions positions
+	// services is the path of the map of services in the file
+	services []string
+	// sources holds the sources of the keys of the parsed services
+	sources map[string]map[string][]Position
+	// tags holds the merge tags of the keys of the file
+	tags map[string]string
+}
+
+func newLocator(file string, data []byte, services ...string) *locator {
+	index, tags := scanPositions(file, data)
+	return &locator{
+		positions: index,
+		services:  services,
+		sources:   map[string]map[string][]Position{},
+		tags:      tags,
+	}
+}
+
+// at returns the position of the value at the specified path of the file,
+// "" if it is unknown.
+func (l *locator) at(keys ...string) string {
+	if l == nil {
+		return ""
+	}
+	if position, ok := l.positions.find(keys...); ok {
+		return position.String()
+	}
+	return ""
+}
+
+// service returns the position of the value at the specified path of a
+// service, "" if it is unknown.
+func (l *locator) service(name string, keys ...string) string {
+	if l == nil {
+		return ""
+	}
+	path := append(append([]string{}, l.services...), name)
+	return l.at(append(path, keys...)...)
+}
+
+// serviceSources returns the sources of the keys of a service of the file:
+// the position of each of them. The env files are sources of the
+// environment.
+func (l *locator) serviceSources(name string, serviceData RawService) map[string][]Position {
+	sources := map[string][]Position{}
+	if l == nil {
+		return sources
+	}
+	for key := range serviceData {
+		path := append(append([]string{}, l.services...), name, key)
+		position, ok := l.positions[strings.Join(path, ".")]
+		if !ok {
+			continue
+		}
+		if key == "env_file" {
+			key = "environment"
+		}
+		sources[key] = append(sources[key], position)
+	}
+	return sources
+}
+
+// serviceTags returns the merge tags of the keys of a service of the file.
+func (l *locator) serviceTags(name string) map[string]string {
+	tags := map[string]string{}
+	if l == nil {
+		return tags
+	}
+	prefix := strings.Join(append(append([]string{}, l.services...), name), ".") + "."
+	for path, tag := range l.tags {
+		if key := strings.TrimPrefix(path, prefix); key != path && !strings.Contains(key, ".") {
+			tags[key] = tag
+		}
+	}
+	return tags
+}
+
+// reset removes the keys tagged !reset from the services of the file: they
+// only remove the values of the services they override, or extend.
+func (l *locator) reset(datas RawServiceMap) {
+	for name, serviceData := range datas {
+		for key, tag := range l.serviceTags(name) {
+			if tag == resetTag {
+				delete(serviceData, key)
+			}
+		}
+	}
+}
+
+// source returns the position of the value of a key of a parsed service,
+// "" if it is unknown.
+func (l *locator) source(name, key string) string {
+	if l == nil {
+		return ""
+	}
+	chain := l.sources[name][key]
+	if len(chain) == 0 {
+		return ""
+	}
+	return chain[len(chain)-1].String()
+}
+
+// chain describes the sources of the values of the specified keys of a parsed
+// service when several files, or extended services, contributed to them: ""
+// otherwise.
+func (l *locator) chain(name string, keys ...string) string {
+	if l == nil {
+		return ""
+	}
+	var chains []string
+	files := map[string]bool{}
+	overridden := false
+	for _, key := range keys {
+		chain := l.sources[name][key]
+		if len(chain) == 0 {
+			continue
+		}
+		chains = append(chains, describeChain(key, chain))
+		for _, position := range chain {
+			files[position.File] = true
+		}
+		overridden = overridden || len(chain) > 1
+	}
+	if !overridden && len(files) < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(chains, ", "))
+}
+
+// wrap locates the errors interpolating the services of the file.
+func (l *locator) wrap(err error) error {
+	if interpolationErr, ok := err.(*interpolationError); ok {
+		return errors.New(locate(l.service(interpolationErr.service, interpolationErr.option), err.Error()))
+	}
+	return err
+}
+
+// locate prefixes a message with the position of the value it is about, if
+// it is known.
+func locate(position, message string) string {
+	if position == "" {
+		return message
+	}
+	return position + ": " + message
+}
+
+// mergeSources returns the sources of the keys of a service overriding, or
+// extending, another one: the keys of the service are appended to the
+// sources of the other one, unless tagged. Like mergeConfig, image and build
+// replace each other.
+func mergeSources(base, sources map[string][]Position, tags map[string]string) map[string][]Position {
+	merged := map[string][]Position{}
+	for key, chain := range base {
+		if _, ok := tags[key]; !ok {
+			merged[key] = append([]Position{}, chain...)
+		}
+	}
+	for key, chain := range sources {
+		switch key {
+		case "image":
+			delete(merged, "build")
+		case "build":
+			delete(merged, "image")
+		}
+		merged[key] = append(merged[key], chain...)
+	}
+	return merged
+}
+
+// describeSources describes the sources of the value of a key, "" if they are
+// unknown.
+func describeSources(key string, chain []Position) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", describeChain(key, chain))
+}
+
+// extendedError locates the errors of the service extended by the service
+// name of the file located with l.
+func extendedError(err error, l *locator, name string) error {
+	if err == nil {
+		return nil
+	}
+	position := l.service(name, "extends")
+	if position == "" {
+		return err
+	}
+	return fmt.Errorf("%v (extended by service '%s' at %s)", err, name, position)
+}
+
+// describeChain describes the positions of the values of a key, from the
+// first one set to the one overriding the others.
+func describeChain(key string, chain []Position) string {
+	var parts []string
+	for _, position := range chain {
+		parts = append(parts, position.String())
+	}
+	return fmt.Sprintf("%s: %s", key, strings.Join(parts, " -> "))
+}
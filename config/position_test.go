@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type filesLookup map[string]string
+
+func (l filesLookup) Lookup(file, relativeTo string) ([]byte, string, error) {
+	content, ok := l[file]
+	if !ok {
+		return nil, "", fmt.Errorf("no such file %s", file)
+	}
+	return []byte(content), file, nil
+}
+
+func (l filesLookup) ResolvePath(path, inFile string) string {
+	return path
+}
+
+func TestParsePositions(t *testing.T) {
+	index := parsePositions("docker-compose.yml", []byte(`version: '2'
+# the services
+services:
+  web:
+    image: nginx
+    command: |
+      nginx
+      -g daemon off;
+    ports:
+    - "80:80"
+    - 443:443
+    environment: [A=1]
+    volumes:
+      - source: data
+        target: /data
+      - /logs
+  "db":
+    image: postgres
+`))
+
+	for path, expected := range map[string]Position{
+		"version":                       {"docker-compose.yml", 1, 1},
+		"services":                      {"docker-compose.yml", 3, 1},
+		"services.web.image":            {"docker-compose.yml", 5, 5},
+		"services.web.ports":            {"docker-compose.yml", 9, 5},
+		"services.web.ports.0":          {"docker-compose.yml", 10, 5},
+		"services.web.ports.1":          {"docker-compose.yml", 11, 5},
+		"services.web.environment":      {"docker-compose.yml", 12, 5},
+		"services.web.environment.0":    {"docker-compose.yml", 12, 19},
+		"services.web.volumes.0":        {"docker-compose.yml", 14, 7},
+		"services.web.volumes.0.source": {"docker-compose.yml", 14, 9},
+		"services.web.volumes.0.target": {"docker-compose.yml", 15, 9},
+		"services.web.volumes.1":        {"docker-compose.yml", 16, 7},
+		"services.db.image":             {"docker-compose.yml", 18, 5},
+	} {
+		assert.Equal(t, expected, index[path], path)
+	}
+	assert.Len(t, index, 17)
+
+	position, ok := index.find("services", "web", "environment", "0", "A")
+	assert.True(t, ok)
+	assert.Equal(t, "docker-compose.yml:12:19", position.String())
+}
+
+func TestParseFlowPositions(t *testing.T) {
+	index, tags := scanPositions("docker-compose.yml", []byte(`version: '2'
+services:
+  web: {image: nginx, ports: !reset [], labels: {a: "x, }"}}
+  worker: &worker
+    command: sh -c
+      "sleep 1; exit 0"
+    environment: [
+      A=1,  # first
+      "B=2"
+    ]
+    dns: !override
+      - 8.8.8.8
+  db:
+    <<: *worker
+    image: postgres
+`))
+
+	for path, expected := range map[string]Position{
+		"services.web":                  {"docker-compose.yml", 3, 3},
+		"services.web.image":            {"docker-compose.yml", 3, 9},
+		"services.web.ports":            {"docker-compose.yml", 3, 23},
+		"services.web.labels":           {"docker-compose.yml", 3, 41},
+		"services.web.labels.a":         {"docker-compose.yml", 3, 50},
+		"services.worker.command":       {"docker-compose.yml", 5, 5},
+		"services.worker.environment":   {"docker-compose.yml", 7, 5},
+		"services.worker.environment.0": {"docker-compose.yml", 8, 7},
+		"services.worker.environment.1": {"docker-compose.yml", 9, 7},
+		"services.worker.dns":           {"docker-compose.yml", 11, 5},
+		"services.worker.dns.0":         {"docker-compose.yml", 12, 7},
+		"services.db.<<":                {"docker-compose.yml", 14, 5},
+		"services.db.image":             {"docker-compose.yml", 15, 5},
+	} {
+		assert.Equal(t, expected, index[path], path)
+	}
+	assert.Len(t, index, 17)
+	assert.Equal(t, map[string]string{
+		"services.web.ports":  "!reset",
+		"services.worker.dns": "!override",
+	}, tags)
+
+	// the keys merged into db are located at the merge key
+	position, ok := index.find("services", "db", "environment")
+	assert.True(t, ok)
+	assert.Equal(t, "docker-compose.yml:13:3", position.String())
+}
+
+func TestValidationErrorPositions(t *testing.T) {
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "docker-compose.yml", []byte(`version: '2.1'
+services:
+  web:
+    image: nginx
+    ports: 80
+  db:
+    image: postgres
+    privilege: true
+`), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "docker-compose.yml:5:5: Service 'web' configuration key 'ports' contains an invalid type")
+	assert.Contains(t, err.Error(), "docker-compose.yml:8:5: Unsupported config option for db service: 'privilege'")
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, &NullLookup{}, "docker-compose.yml", []byte(`version: '2.1'
+services:
+  web:
+    image: nginx
+secrets:
+  key:
+    file: key.pem
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "docker-compose.yml:5:1: Unsupported top level key 'secrets' for version 2.1", err.Error())
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), emptyEnvironmentLookup{}, &NullLookup{}, "docker-compose.yml", []byte(`web:
+  image: ${IMAGE?set IMAGE}
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, `docker-compose.yml:2:3: Missing mandatory value for "image" option in service "web": set IMAGE`, err.Error())
+}
+
+func TestExtendsErrorChain(t *testing.T) {
+	lookup := filesLookup{
+		"base.yml": `web:
+  build: .
+  dockerfile: Dockerfile.dev
+`,
+		"worker.yml": `worker:
+  image: worker
+  ports: 80
+`,
+	}
+
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`web:
+  extends:
+    file: base.yml
+    service: web
+  image: web
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "base.yml:3:3: Service 'web' has both an image and alternate Dockerfile. A service can either be built to image or use an existing image, not both. (image: docker-compose.yml:5:3, dockerfile: base.yml:3:3)", err.Error())
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`worker:
+  extends:
+    file: worker.yml
+    service: worker
+`), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "worker.yml:3:3: Service 'worker' configuration key 'ports' contains an invalid type")
+	assert.Contains(t, err.Error(), "(extended by service 'worker' at docker-compose.yml:2:3)")
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`web:
+  extends:
+    service: db
+  image: web
+`), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "docker-compose.yml:3:5: Failed to find service db to extend", err.Error())
+}
+
+func TestOverrideSources(t *testing.T) {
+	serviceConfigs := NewServiceConfigs()
+	_, configs, _, _, err := Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - 80
+`), nil)
+	assert.Nil(t, err)
+	serviceConfigs.Add("web", configs["web"])
+
+	_, configs, _, _, err = Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.override.yml", []byte(`version: '2'
+services:
+  web:
+    ports:
+      - 443
+`), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]Position{
+		"image": {{"docker-compose.yml", 4, 5}},
+		"ports": {{"docker-compose.yml", 5, 5}, {"docker-compose.override.yml", 4, 5}},
+	}, configs["web"].Sources)
+
+	// the sources are not part of the service
+	moved := *configs["web"]
+	moved.Sources = nil
+	assert.Equal(t, GetServiceHash("web", configs["web"]), GetServiceHash("web", &moved))
+}
@@ -12,8 +12,27 @@ var (
 	schemaLoader           gojsonschema.JSONLoader
 	constraintSchemaLoader gojsonschema.JSONLoader
 	schema                 map[string]interface{}
+
+	// schemas of the file format versions validated after v1, by the
+	// versions returned by schemaVersion. Version 2.0 files have never been
+	// validated, many in use would not be.
+	versionSchemas = map[string]*versionSchema{
+		"2.1": {source: schemaV21},
+		"3":   {source: schemaV3},
+	}
 )
 
+// versionSchema holds the loaders of the schema of a file format version:
+// one validating the top level keys, one validating the services section like
+// v1 files, and one validating the constraints of a service.
+type versionSchema struct {
+	source      string
+	schema      map[string]interface{}
+	document    gojsonschema.JSONLoader
+	services    gojsonschema.JSONLoader
+	constraints gojsonschema.JSONLoader
+}
+
 type (
 	environmentFormatChecker struct{}
 	portsFormatChecker       struct{}
@@ -54,6 +73,38 @@ func setupSchemaLoaders() error {
 	service := constraints["service"].(map[string]interface{})
 	constraintSchemaLoader = gojsonschema.NewGoLoader(service)
 
+	for _, versionSchema := range versionSchemas {
+		if err := versionSchema.setup(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *versionSchema) setup() error {
+	var schemaRaw interface{}
+	if err := json.Unmarshal([]byte(v.source), &schemaRaw); err != nil {
+		return err
+	}
+
+	v.schema = schemaRaw.(map[string]interface{})
+	v.document = gojsonschema.NewGoLoader(v.schema)
+
+	properties := v.schema["properties"].(map[string]interface{})
+	services := properties["services"].(map[string]interface{})
+	v.services = gojsonschema.NewGoLoader(map[string]interface{}{
+		"$schema":              v.schema["$schema"],
+		"type":                 "object",
+		"patternProperties":    services["patternProperties"],
+		"additionalProperties": false,
+		"definitions":          v.schema["definitions"],
+	})
+
+	definitions := v.schema["definitions"].(map[string]interface{})
+	constraints := definitions["constraints"].(map[string]interface{})
+	v.constraints = gojsonschema.NewGoLoader(constraints["service"])
+
 	return nil
 }
 
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"path"
+	"strings"
+
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+)
+
+// SecretsDir is the directory of the containers where the secrets and the
+// configs of their service are mounted.
+const SecretsDir = "/run/secrets"
+
+// Path returns the path of the file in the containers of the service: its
+// target, relative to SecretsDir unless absolute, SecretsDir/<source> by
+// default.
+func (f ServiceFile) Path() string {
+	target := f.Target
+	if target == "" {
+		target = f.Source
+	}
+	if path.IsAbs(target) {
+		return target
+	}
+	return path.Join(SecretsDir, target)
+}
+
+// ParseSecrets parses the secrets of a compose file, resolving their files
+// relatively to it.
+func ParseSecrets(resourceLookup ResourceLookup, inFile string, bytes []byte) (map[string]*FileConfig, error) {
+	var config Config
+	if err := yaml.Unmarshal(bytes, &config); err != nil {
+		return nil, err
+	}
+	return resolveFiles(resourceLookup, inFile, config.Secrets), nil
+}
+
+// ParseConfigs parses the configs of a compose file, resolving their files
+// relatively to it.
+func ParseConfigs(resourceLookup ResourceLookup, inFile string, bytes []byte) (map[string]*FileConfig, error) {
+	var config Config
+	if err := yaml.Unmarshal(bytes, &config); err != nil {
+		return nil, err
+	}
+	return resolveFiles(resourceLookup, inFile, config.Configs), nil
+}
+
+func resolveFiles(resourceLookup ResourceLookup, inFile string, files map[string]*FileConfig) map[string]*FileConfig {
+	for name, file := range files {
+		if file == nil {
+			files[name] = &FileConfig{}
+			continue
+		}
+		if file.File == "" || resourceLookup == nil {
+			continue
+		}
+		// the lookups resolve the host path of the volumes ("host:container")
+		bind := resourceLookup.ResolvePath(file.File+":"+SecretsDir, inFile)
+		file.File = strings.TrimSuffix(bind, ":"+SecretsDir)
+	}
+	return files
+}
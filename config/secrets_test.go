@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type prefixLookup struct {
+	NullLookup
+}
+
+func (l *prefixLookup) ResolvePath(path, inFile string) string {
+	if strings.HasPrefix(path, "/") {
+		return path
+	}
+	return "/project/" + strings.TrimPrefix(path, "./")
+}
+
+func TestServiceFilePath(t *testing.T) {
+	assert.Equal(t, "/run/secrets/password", ServiceFile{Source: "password"}.Path())
+	assert.Equal(t, "/run/secrets/db/password", ServiceFile{Source: "password", Target: "db/password"}.Path())
+	assert.Equal(t, "/etc/nginx.conf", ServiceFile{Source: "nginx", Target: "/etc/nginx.conf"}.Path())
+}
+
+func TestParseSecretsAndConfigs(t *testing.T) {
+	bytes := []byte(`
+version: '3.3'
+services:
+  web:
+    image: nginx
+secrets:
+  password:
+    file: ./password.txt
+  certificate:
+    file: /etc/ssl/web.pem
+  token:
+    external:
+      name: web-token
+configs:
+  nginx:
+    file: nginx.conf
+`)
+
+	secrets, err := ParseSecrets(&prefixLookup{}, "docker-compose.yml", bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, "/project/password.txt", secrets["password"].File)
+	assert.Equal(t, "/etc/ssl/web.pem", secrets["certificate"].File)
+	assert.True(t, secrets["token"].External.External)
+	assert.Equal(t, "web-token", secrets["token"].External.Name)
+
+	configs, err := ParseConfigs(&prefixLookup{}, "docker-compose.yml", bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*FileConfig{"nginx": {File: "/project/nginx.conf"}}, configs)
+
+	// without a lookup the files are kept as they are
+	configs, err = ParseConfigs(nil, "docker-compose.yml", bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, "nginx.conf", configs["nginx"].File)
+}
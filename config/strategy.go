@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The merge tags of the keys of a service overriding, or extending, another
+// one: !reset removes the value of the other service, and !override replaces
+// it instead of merging it.
+const (
+	resetTag    = "!reset"
+	overrideTag = "!override"
+)
+
+// mergeStrategy merges the value of a key of a service overriding, or
+// extending, another one into the value of the other one.
+type mergeStrategy func(existing, value interface{}) interface{}
+
+// mergeStrategies holds the strategies of the keys merged like
+// docker-compose does, the other ones are merged with merge.
+var mergeStrategies = map[string]mergeStrategy{
+	"command":        replace,
+	"entrypoint":     replace,
+	"environment":    mergeByKey,
+	"labels":         mergeByKey,
+	"volumes":        mergeByPath,
+	"devices":        mergeByPath,
+	"ports":          mergeUnique,
+	"expose":         mergeUnique,
+	"dns":            mergeUnique,
+	"dns_search":     mergeUnique,
+	"external_links": mergeUnique,
+	"cap_add":        mergeUnique,
+	"cap_drop":       mergeUnique,
+	"security_opt":   mergeUnique,
+	"volumes_from":   mergeUnique,
+}
+
+// mergeValue merges the value of a key with the strategy of the key.
+func mergeValue(key string, existing, value interface{}) interface{} {
+	if strategy, ok := mergeStrategies[key]; ok {
+		return strategy(existing, value)
+	}
+	return merge(existing, value)
+}
+
+func replace(existing, value interface{}) interface{} {
+	return value
+}
+
+// mergeUnique appends the items of value that existing does not have. A
+// string is a list of one item.
+func mergeUnique(existing, value interface{}) interface{} {
+	left, lok := asList(existing)
+	right, rok := asList(value)
+	if !lok || !rok {
+		return value
+	}
+
+	merged := append([]interface{}{}, left...)
+	seen := map[string]bool{}
+	for _, item := range left {
+		seen[fmt.Sprint(item)] = true
+	}
+	for _, item := range right {
+		if !seen[fmt.Sprint(item)] {
+			seen[fmt.Sprint(item)] = true
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// mergeByKey merges the "key=value" items, or the maps, of environment
+// variables and labels by key: the values of value replace the ones of
+// existing.
+func mergeByKey(existing, value interface{}) interface{} {
+	leftMap, lok := existing.(map[interface{}]interface{})
+	rightMap, rok := value.(map[interface{}]interface{})
+	if lok && rok {
+		return merge(leftMap, rightMap)
+	}
+
+	left, lok := keyedItems(existing)
+	right, rok := keyedItems(value)
+	if !lok || !rok {
+		return value
+	}
+	return mergeItems(left, right)
+}
+
+// mergeByPath merges the volumes, or devices, by container path: the ones of
+// value replace the ones of existing mounted at the same path.
+func mergeByPath(existing, value interface{}) interface{} {
+	left, lok := existing.([]interface{})
+	right, rok := value.([]interface{})
+	if !lok || !rok {
+		return value
+	}
+
+	var leftItems, rightItems []keyedItem
+	for _, item := range left {
+		leftItems = append(leftItems, keyedItem{containerPath(item), item})
+	}
+	for _, item := range right {
+		rightItems = append(rightItems, keyedItem{containerPath(item), item})
+	}
+	return mergeItems(leftItems, rightItems)
+}
+
+type keyedItem struct {
+	key   string
+	value interface{}
+}
+
+// mergeItems replaces the items of left by the items of right with the same
+// key, in place, and appends the other items of right.
+func mergeItems(left, right []keyedItem) []interface{} {
+	index := map[string]int{}
+	var merged []interface{}
+	for _, item := range left {
+		index[item.key] = len(merged)
+		merged = append(merged, item.value)
+	}
+	for _, item := range right {
+		if i, ok := index[item.key]; ok {
+			merged[i] = item.value
+			continue
+		}
+		index[item.key] = len(merged)
+		merged = append(merged, item.value)
+	}
+	return merged
+}
+
+// keyedItems returns the "key=value" items of a list, or of a map, with
+// their keys.
+func keyedItems(value interface{}) ([]keyedItem, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		var items []keyedItem
+		for _, item := range value {
+			items = append(items, keyedItem{strings.SplitN(fmt.Sprint(item), "=", 2)[0], item})
+		}
+		return items, true
+	case map[interface{}]interface{}:
+		values := map[string]interface{}{}
+		var keys []string
+		for key, item := range value {
+			values[fmt.Sprint(key)] = item
+			keys = append(keys, fmt.Sprint(key))
+		}
+		sort.Strings(keys)
+		var items []keyedItem
+		for _, key := range keys {
+			item := values[key]
+			if item == nil {
+				items = append(items, keyedItem{key, key})
+			} else {
+				items = append(items, keyedItem{key, fmt.Sprintf("%s=%v", key, item)})
+			}
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// containerPath returns the path a volume, or a device, is mounted at in the
+// container: the target of the long syntax, or the path after the host path
+// or the volume name of the short one.
+func containerPath(item interface{}) string {
+	if volume, ok := item.(map[interface{}]interface{}); ok {
+		return asString(volume["target"])
+	}
+	parts := strings.Split(fmt.Sprint(item), ":")
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[1]
+}
+
+func asList(value interface{}) ([]interface{}, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		return value, true
+	case string:
+		return []interface{}{value}, true
+	}
+	return nil, false
+}
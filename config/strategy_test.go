@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeStrategies(t *testing.T) {
+	cases := []struct {
+		key      string
+		existing interface{}
+		value    interface{}
+		expected interface{}
+	}{
+		{"command", []interface{}{"nginx", "-g"}, []interface{}{"sh"}, []interface{}{"sh"}},
+		{"ports", []interface{}{"80", 443}, []interface{}{"80", "8080:80"}, []interface{}{"80", 443, "8080:80"}},
+		{"dns", "8.8.8.8", []interface{}{"8.8.8.8", "8.8.4.4"}, []interface{}{"8.8.8.8", "8.8.4.4"}},
+		{"environment", []interface{}{"A=1", "B=2", "C"}, []interface{}{"B=3", "D=4"}, []interface{}{"A=1", "B=3", "C", "D=4"}},
+		{"environment", []interface{}{"A=1", "B=2"}, map[interface{}]interface{}{"B": 3, "C": nil}, []interface{}{"A=1", "B=3", "C"}},
+		{"labels", map[interface{}]interface{}{"a": "1"}, map[interface{}]interface{}{"b": "2"}, map[interface{}]interface{}{"a": "1", "b": "2"}},
+		{"volumes", []interface{}{"/data", "./logs:/logs:ro"}, []interface{}{"logs:/logs", map[interface{}]interface{}{"source": "cache", "target": "/cache"}}, []interface{}{"/data", "logs:/logs", map[interface{}]interface{}{"source": "cache", "target": "/cache"}}},
+		{"devices", []interface{}{"/dev/sda:/dev/xvda"}, []interface{}{"/dev/sdb:/dev/xvda:rwm"}, []interface{}{"/dev/sdb:/dev/xvda:rwm"}},
+		{"extra_hosts", []interface{}{"a:1.1.1.1"}, []interface{}{"a:1.1.1.1"}, []interface{}{"a:1.1.1.1", "a:1.1.1.1"}},
+		{"image", "nginx", "httpd", "httpd"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, mergeValue(c.key, c.existing, c.value), c.key)
+	}
+}
+
+func TestOverrideTags(t *testing.T) {
+	serviceConfigs := NewServiceConfigs()
+	_, configs, _, _, err := Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    image: nginx
+    command: [nginx, -g, daemon off;]
+    ports:
+      - 80
+      - 443
+    environment:
+      A: "1"
+      B: "2"
+    dns: 8.8.8.8
+`), nil)
+	assert.Nil(t, err)
+	serviceConfigs.Add("web", configs["web"])
+
+	_, configs, _, _, err = Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.override.yml", []byte(`version: '2'
+services:
+  web:
+    ports: !override
+      - 8443:443
+    environment: !reset
+    dns: !reset []
+    command: [nginx-debug]
+`), nil)
+	assert.Nil(t, err)
+	web := configs["web"]
+	assert.Equal(t, "nginx", web.Image)
+	assert.Equal(t, []string{"nginx-debug"}, []string(web.Command))
+	assert.Equal(t, []string{"8443:443"}, web.Ports)
+	assert.Nil(t, web.Environment)
+	assert.Nil(t, web.DNS)
+	assert.Equal(t, map[string][]Position{
+		"image":   {{"docker-compose.yml", 4, 5}},
+		"command": {{"docker-compose.yml", 5, 5}, {"docker-compose.override.yml", 8, 5}},
+		"ports":   {{"docker-compose.override.yml", 4, 5}},
+	}, web.Sources)
+}
+
+func TestFlowOverrideTags(t *testing.T) {
+	serviceConfigs := NewServiceConfigs()
+	_, configs, _, _, err := Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.yml", []byte(`version: '2'
+services:
+  web:
+    image: nginx
+    ports: [80, 443]
+    dns: 8.8.8.8
+`), nil)
+	assert.Nil(t, err)
+	serviceConfigs.Add("web", configs["web"])
+
+	_, configs, _, _, err = Merge(serviceConfigs, nil, &NullLookup{}, "docker-compose.override.yml", []byte(`version: '2'
+services:
+  web: {ports: !reset [], dns: !override [8.8.4.4]}
+`), nil)
+	assert.Nil(t, err)
+	web := configs["web"]
+	assert.Nil(t, web.Ports)
+	assert.Equal(t, []string{"8.8.4.4"}, []string(web.DNS))
+}
+
+func TestExtendsTags(t *testing.T) {
+	lookup := filesLookup{
+		"base.yml": `base:
+  image: nginx
+  ports:
+    - 80
+  volumes:
+    - /data
+    - ./logs:/logs
+`,
+	}
+
+	_, configs, _, _, err := Merge(NewServiceConfigs(), nil, lookup, "docker-compose.yml", []byte(`web:
+  extends:
+    file: base.yml
+    service: base
+  ports: !reset
+  volumes:
+    - /var/log/web:/logs
+`), nil)
+	assert.Nil(t, err)
+	web := configs["web"]
+	assert.Nil(t, web.Ports)
+	assert.Equal(t, []string{"/data", "/var/log/web:/logs"}, web.Volumes)
+}
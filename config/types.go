@@ -66,6 +66,12 @@ type ServiceConfigV1 struct {
 	LogOpt        map[string]string    `yaml:"log_opt,omitempty"`
 	ExtraHosts    []string             `yaml:"extra_hosts,omitempty"`
 	Ulimits       yaml.Ulimits         `yaml:"ulimits,omitempty"`
+
+	// Extensions holds the raw values of the x- keys of the service.
+	Extensions map[string]interface{} `yaml:"-"`
+	// Sources holds the positions of the values of the keys of the service,
+	// from the first one set to the one overriding the others.
+	Sources map[string][]Position `yaml:"-"`
 }
 
 // Log holds v2 logging information
@@ -86,7 +92,9 @@ type ServiceConfig struct {
 	CgroupParent  string               `yaml:"cgroup_parent,omitempty"`
 	ContainerName string               `yaml:"container_name,omitempty"`
 	Devices       []string             `yaml:"devices,omitempty"`
+	Configs       []ServiceFile        `yaml:"configs,omitempty"`
 	DependsOn     []string             `yaml:"depends_on,omitempty"`
+	Deploy        DeployConfig         `yaml:"deploy,omitempty"`
 	DNS           yaml.Stringorslice   `yaml:"dns,omitempty"`
 	DNSSearch     yaml.Stringorslice   `yaml:"dns_search,omitempty"`
 	DomainName    string               `yaml:"domain_name,omitempty"`
@@ -97,6 +105,7 @@ type ServiceConfig struct {
 	Extends       yaml.MaporEqualSlice `yaml:"extends,omitempty"`
 	ExternalLinks []string             `yaml:"external_links,omitempty"`
 	ExtraHosts    []string             `yaml:"extra_hosts,omitempty"`
+	HealthCheck   *HealthCheckConfig   `yaml:"healthcheck,omitempty"`
 	Image         string               `yaml:"image,omitempty"`
 	Hostname      string               `yaml:"hostname,omitempty"`
 	Ipc           string               `yaml:"ipc,omitempty"`
@@ -111,6 +120,8 @@ type ServiceConfig struct {
 	Pid           string               `yaml:"pid,omitempty"`
 	Ports         []string             `yaml:"ports,omitempty"`
 	Privileged    bool                 `yaml:"privileged,omitempty"`
+	Profiles      []string             `yaml:"profiles,omitempty"`
+	Secrets       []ServiceFile        `yaml:"secrets,omitempty"`
 	SecurityOpt   []string             `yaml:"security_opt,omitempty"`
 	StopSignal    string               `yaml:"stop_signal,omitempty"`
 	VolumeDriver  string               `yaml:"volume_driver,omitempty"`
@@ -124,6 +135,87 @@ type ServiceConfig struct {
 	User          string               `yaml:"user,omitempty"`
 	WorkingDir    string               `yaml:"working_dir,omitempty"`
 	Ulimits       yaml.Ulimits         `yaml:"ulimits,omitempty"`
+
+	// Extensions holds the raw values of the x- keys of the service.
+	Extensions map[string]interface{} `yaml:"-"`
+	// Sources holds the positions of the values of the keys of the service,
+	// from the first one set to the one overriding the others.
+	Sources map[string][]Position `yaml:"-"`
+}
+
+// DeployConfig holds v3 deploy configuration
+type DeployConfig struct {
+	Mode          string          `yaml:"mode,omitempty"`
+	Replicas      *int            `yaml:"replicas,omitempty"`
+	Labels        yaml.SliceorMap `yaml:"labels,omitempty"`
+	UpdateConfig  *UpdateConfig   `yaml:"update_config,omitempty"`
+	Resources     Resources       `yaml:"resources,omitempty"`
+	RestartPolicy *RestartPolicy  `yaml:"restart_policy,omitempty"`
+	Placement     Placement       `yaml:"placement,omitempty"`
+}
+
+// UpdateConfig holds v3 rolling update configuration
+type UpdateConfig struct {
+	Parallelism     *int    `yaml:"parallelism,omitempty"`
+	Delay           string  `yaml:"delay,omitempty"`
+	FailureAction   string  `yaml:"failure_action,omitempty"`
+	Monitor         string  `yaml:"monitor,omitempty"`
+	MaxFailureRatio float64 `yaml:"max_failure_ratio,omitempty"`
+	Order           string  `yaml:"order,omitempty"`
+}
+
+// Resources holds v3 resource limits and reservations
+type Resources struct {
+	Limits       *Resource `yaml:"limits,omitempty"`
+	Reservations *Resource `yaml:"reservations,omitempty"`
+}
+
+// Resource holds v3 cpu and memory resources
+type Resource struct {
+	CPUs   string `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// RestartPolicy holds v3 restart policy
+type RestartPolicy struct {
+	Condition   string `yaml:"condition,omitempty"`
+	Delay       string `yaml:"delay,omitempty"`
+	MaxAttempts *int   `yaml:"max_attempts,omitempty"`
+	Window      string `yaml:"window,omitempty"`
+}
+
+// Placement holds v3 placement constraints
+type Placement struct {
+	Constraints []string `yaml:"constraints,omitempty"`
+}
+
+// HealthCheckConfig holds v2.1 and v3 healthcheck configuration. A string
+// test is parsed as ["CMD-SHELL", test].
+type HealthCheckConfig struct {
+	Test        yaml.Stringorslice `yaml:"test,omitempty"`
+	Interval    string             `yaml:"interval,omitempty"`
+	Timeout     string             `yaml:"timeout,omitempty"`
+	Retries     int                `yaml:"retries,omitempty"`
+	StartPeriod string             `yaml:"start_period,omitempty"`
+	Disable     bool               `yaml:"disable,omitempty"`
+}
+
+// ServiceFile holds a v3 reference of a service to a secret or a config. The
+// short syntax ("name") is parsed as its source.
+type ServiceFile struct {
+	Source string `yaml:"source,omitempty"`
+	Target string `yaml:"target,omitempty"`
+	UID    string `yaml:"uid,omitempty"`
+	GID    string `yaml:"gid,omitempty"`
+	Mode   *int   `yaml:"mode,omitempty"`
+}
+
+// FileConfig holds v3 secret and config configuration. The file of a
+// secret or a config is resolved relatively to the compose file defining it.
+type FileConfig struct {
+	File     string          `yaml:"file,omitempty"`
+	External yaml.External   `yaml:"external,omitempty"`
+	Labels   yaml.SliceorMap `yaml:"labels,omitempty"`
 }
 
 // VolumeConfig holds v2 volume configuration
@@ -161,6 +253,11 @@ type Config struct {
 	Services RawServiceMap             `yaml:"services,omitempty"`
 	Volumes  map[string]*VolumeConfig  `yaml:"volumes,omitempty"`
 	Networks map[string]*NetworkConfig `yaml:"networks,omitempty"`
+	Secrets  map[string]*FileConfig    `yaml:"secrets,omitempty"`
+	Configs  map[string]*FileConfig    `yaml:"configs,omitempty"`
+
+	// Extensions holds the raw values of the top level x- keys, set by ParseConfig.
+	Extensions map[string]interface{} `yaml:"-"`
 }
 
 // NewServiceConfigs initializes a new Configs struct
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -188,11 +189,27 @@ func invalidTypeMessage(service, key string, err gojsonschema.ResultError) strin
 	return fmt.Sprintf("Service '%s' configuration key '%s' contains an invalid type, it should be %s.", service, key, validTypesMsg)
 }
 
-func validate(serviceMap RawServiceMap) error {
+// errorKeys returns the path of the value an error is about in the validated
+// map: the keys of its context, and its property if any.
+func errorKeys(err gojsonschema.ResultError) []string {
+	keys := strings.Split(err.Context().String(), ".")[1:]
+	if property, ok := err.Details()["property"].(string); ok {
+		keys = append(keys, property)
+	}
+	return keys
+}
+
+func validate(serviceMap RawServiceMap, l *locator) error {
 	if err := setupSchemaLoaders(); err != nil {
 		return err
 	}
 
+	return validateServices(serviceMap, l, schemaLoader, schema)
+}
+
+// validateServices validates a map of services with the specified schema,
+// whose root is the map of services. The errors are located with l.
+func validateServices(serviceMap RawServiceMap, l *locator, schemaLoader gojsonschema.JSONLoader, schema map[string]interface{}) error {
 	serviceMap = convertServiceMapKeysToStrings(serviceMap)
 
 	var validationErrors []string
@@ -217,10 +234,15 @@ func validate(serviceMap RawServiceMap) error {
 				continue
 			}
 
+			position := ""
+			if keys := errorKeys(err); len(keys) > 0 {
+				position = l.service(keys[0], keys[1:]...)
+			}
+
 			if err.Context().String() == "(root)" {
 				switch err.Type() {
 				case "additional_property_not_allowed":
-					validationErrors = append(validationErrors, fmt.Sprintf("Invalid service name '%s' - only [a-zA-Z0-9\\._\\-] characters are allowed", err.Field()))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Invalid service name '%s' - only [a-zA-Z0-9\\._\\-] characters are allowed", err.Field())))
 				default:
 					validationErrors = append(validationErrors, err.Description())
 				}
@@ -232,39 +254,45 @@ func validate(serviceMap RawServiceMap) error {
 
 				switch err.Type() {
 				case "additional_property_not_allowed":
-					validationErrors = append(validationErrors, unsupportedConfigMessage(key, result.Errors()[i+1]))
+					validationErrors = append(validationErrors, locate(position, unsupportedConfigMessage(key, result.Errors()[i+1])))
 				case "number_one_of":
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' configuration key '%s' %s", serviceName, key, oneOfMessage(serviceMap, schema, err, result.Errors()[i+1])))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Service '%s' configuration key '%s' %s", serviceName, key, oneOfMessage(serviceMap, schema, err, result.Errors()[i+1]))))
 
 					// Next error handled in oneOfMessage, skip over it
 					i++
 				case "invalid_type":
-					validationErrors = append(validationErrors, invalidTypeMessage(serviceName, key, err))
+					validationErrors = append(validationErrors, locate(position, invalidTypeMessage(serviceName, key, err)))
 				case "required":
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' option '%s' is invalid, %s", serviceName, key, err.Description()))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Service '%s' option '%s' is invalid, %s", serviceName, key, err.Description())))
 				case "missing_dependency":
 					dependency := err.Details()["dependency"].(string)
-					validationErrors = append(validationErrors, fmt.Sprintf("Invalid configuration for '%s' service: dependency '%s' is not satisfied", serviceName, dependency))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Invalid configuration for '%s' service: dependency '%s' is not satisfied", serviceName, dependency)))
 				case "unique":
 					contextWithDuplicates := getValue(serviceMap, err.Context().String())
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' configuration key '%s' value %s has non-unique elements", serviceName, key, contextWithDuplicates))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Service '%s' configuration key '%s' value %s has non-unique elements", serviceName, key, contextWithDuplicates)))
 				default:
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' configuration key %s value %s", serviceName, key, err.Description()))
+					validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Service '%s' configuration key %s value %s", serviceName, key, err.Description())))
 				}
 			}
 		}
 
-		return fmt.Errorf(strings.Join(validationErrors, "\n"))
+		return errors.New(strings.Join(validationErrors, "\n"))
 	}
 
 	return nil
 }
 
-func validateServiceConstraints(service RawService, serviceName string) error {
+func validateServiceConstraints(service RawService, serviceName string, l *locator) error {
 	if err := setupSchemaLoaders(); err != nil {
 		return err
 	}
 
+	return validateConstraints(service, serviceName, l, constraintSchemaLoader)
+}
+
+// validateConstraints validates a parsed service with the specified schema.
+// The errors are located with the sources of the service in l.
+func validateConstraints(service RawService, serviceName string, l *locator, constraintSchemaLoader gojsonschema.JSONLoader) error {
 	service = convertServiceKeysToStrings(service)
 
 	var validationErrors []string
@@ -284,16 +312,16 @@ func validateServiceConstraints(service RawService, serviceName string) error {
 				_, containsDockerfile := service["dockerfile"]
 
 				if containsImage && containsBuild {
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' has both an image and build path specified. A service can either be built to image or use an existing image, not both.", serviceName))
+					validationErrors = append(validationErrors, locate(l.source(serviceName, "build"), fmt.Sprintf("Service '%s' has both an image and build path specified. A service can either be built to image or use an existing image, not both.", serviceName)+l.chain(serviceName, "image", "build")))
 				} else if !containsImage && !containsBuild {
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' has neither an image nor a build path specified. Exactly one must be provided.", serviceName))
+					validationErrors = append(validationErrors, locate(l.service(serviceName), fmt.Sprintf("Service '%s' has neither an image nor a build path specified. Exactly one must be provided.", serviceName)))
 				} else if containsImage && containsDockerfile {
-					validationErrors = append(validationErrors, fmt.Sprintf("Service '%s' has both an image and alternate Dockerfile. A service can either be built to image or use an existing image, not both.", serviceName))
+					validationErrors = append(validationErrors, locate(l.source(serviceName, "dockerfile"), fmt.Sprintf("Service '%s' has both an image and alternate Dockerfile. A service can either be built to image or use an existing image, not both.", serviceName)+l.chain(serviceName, "image", "dockerfile")))
 				}
 			}
 		}
 
-		return fmt.Errorf(strings.Join(validationErrors, "\n"))
+		return errors.New(strings.Join(validationErrors, "\n"))
 	}
 
 	return nil
@@ -10,11 +10,11 @@ import (
 )
 
 func testValidSchema(t *testing.T, serviceMap RawServiceMap) {
-	err := validate(serviceMap)
+	err := validate(serviceMap, nil)
 	assert.Nil(t, err)
 
 	for name, service := range serviceMap {
-		err := validateServiceConstraints(service, name)
+		err := validateServiceConstraints(service, name, nil)
 		assert.Nil(t, err)
 	}
 }
@@ -22,14 +22,14 @@ func testValidSchema(t *testing.T, serviceMap RawServiceMap) {
 func testInvalidSchema(t *testing.T, serviceMap RawServiceMap, errMsgs []string, errCount int) {
 	var combinedErrMsg bytes.Buffer
 
-	err := validate(serviceMap)
+	err := validate(serviceMap, nil)
 	if err != nil {
 		combinedErrMsg.WriteString(err.Error())
 		combinedErrMsg.WriteRune('\n')
 	}
 
 	for name, service := range serviceMap {
-		err := validateServiceConstraints(service, name)
+		err := validateServiceConstraints(service, name, nil)
 		if err != nil {
 			combinedErrMsg.WriteString(err.Error())
 			combinedErrMsg.WriteRune('\n')
@@ -352,3 +352,19 @@ func TestMultipleInvalidServicesForMultipleErrors(t *testing.T) {
 		"Service 'foo2' configuration key 'environment' contains non unique items, please remove duplicates from [KEY=VAL KEY=VAL]",
 	}, 4)
 }
+
+func TestValidKomposeExtensions(t *testing.T) {
+	testValidSchema(t, RawServiceMap{
+		"web": map[string]interface{}{
+			"image": "nginx",
+		},
+		"proxy": map[string]interface{}{
+			"image":                "haproxy",
+			"x-kompose-sidecar-of": "web",
+		},
+		"migrate": map[string]interface{}{
+			"image":             "flyway",
+			"x-kompose-init-of": "web",
+		},
+	})
+}
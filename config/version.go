@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaVersion returns the version of the schema validating the specified
+// version of the file format: "1" for files without a version, "2.0", "2.1"
+// for the later 2.x versions, or "3" for all 3.x versions.
+func schemaVersion(version string) (string, error) {
+	if version == "" {
+		return "1", nil
+	}
+
+	parts := strings.SplitN(version, ".", 2)
+	minor := 0
+	if len(parts) == 2 {
+		var err error
+		if minor, err = strconv.Atoi(parts[1]); err != nil || minor < 0 {
+			return "", fmt.Errorf("Invalid version of the compose file format: %s", version)
+		}
+	}
+
+	switch {
+	case parts[0] == "2" && minor == 0:
+		return "2.0", nil
+	case parts[0] == "2":
+		return "2.1", nil
+	case parts[0] == "3":
+		return "3", nil
+	}
+	return "", fmt.Errorf("Unsupported version of the compose file format: %s, supported versions are 1, 2.x and 3.x", version)
+}
+
+// validateVersion validates the top level keys of a file and its services
+// with the schema of the specified version. The errors are located with l.
+func validateVersion(version string, data map[string]interface{}, serviceMap RawServiceMap, l *locator) error {
+	if err := setupSchemaLoaders(); err != nil {
+		return err
+	}
+	versionSchema := versionSchemas[version]
+
+	topLevel := map[string]interface{}{}
+	for key, value := range data {
+		if key != "services" {
+			topLevel[key] = convertKeysToStrings(value)
+		}
+	}
+
+	result, err := gojsonschema.Validate(versionSchema.document, gojsonschema.NewGoLoader(topLevel))
+	if err != nil {
+		return err
+	}
+	if !result.Valid() {
+		var validationErrors []string
+		for _, err := range result.Errors() {
+			position := l.at(errorKeys(err)...)
+			if err.Type() == "additional_property_not_allowed" && err.Context().String() == "(root)" {
+				validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Unsupported top level key '%s' for version %s", err.Details()["property"], version)))
+				continue
+			}
+			validationErrors = append(validationErrors, locate(position, fmt.Sprintf("Top level key '%s' is invalid: %s", err.Field(), err.Description())))
+		}
+		return errors.New(strings.Join(validationErrors, "\n"))
+	}
+
+	return validateServices(serviceMap, l, versionSchema.services, versionSchema.schema)
+}
+
+// validateVersionConstraints validates the constraints of a parsed service
+// with the schema of the specified version.
+func validateVersionConstraints(version string, service RawService, serviceName string, l *locator) error {
+	if err := setupSchemaLoaders(); err != nil {
+		return err
+	}
+	return validateConstraints(service, serviceName, l, versionSchemas[version].constraints)
+}
+
+// normalizeService rewrites the syntaxes introduced after version 2.0 into
+// the ones ServiceConfig parses: the long syntax of ports and volumes, the
+// dependencies with conditions and the string healthcheck tests. It also
+// rewrites the short syntax of secrets and configs into the long one.
+func normalizeService(name string, serviceData RawService) RawService {
+	if ports, ok := serviceData["ports"].([]interface{}); ok {
+		for i, port := range ports {
+			if portMap, ok := port.(map[interface{}]interface{}); ok {
+				ports[i] = longPort(portMap)
+			}
+		}
+	}
+
+	if volumes, ok := serviceData["volumes"].([]interface{}); ok {
+		var shortVolumes []interface{}
+		for _, volume := range volumes {
+			volumeMap, ok := volume.(map[interface{}]interface{})
+			if !ok {
+				shortVolumes = append(shortVolumes, volume)
+				continue
+			}
+			if asString(volumeMap["type"]) == "tmpfs" {
+				logrus.Warnf("Service %s mounts a tmpfs volume at %s, which is not supported", name, asString(volumeMap["target"]))
+				continue
+			}
+			shortVolumes = append(shortVolumes, longVolume(volumeMap))
+		}
+		serviceData["volumes"] = shortVolumes
+	}
+
+	for _, key := range []string{"secrets", "configs"} {
+		if files, ok := serviceData[key].([]interface{}); ok {
+			for i, file := range files {
+				if source, ok := file.(string); ok {
+					files[i] = map[interface{}]interface{}{"source": source}
+				}
+			}
+		}
+	}
+
+	// the conditions of 2.1 dependencies are not supported
+	if dependsOn, ok := serviceData["depends_on"].(map[interface{}]interface{}); ok {
+		var dependencies []string
+		for dependency := range dependsOn {
+			dependencies = append(dependencies, asString(dependency))
+		}
+		sort.Strings(dependencies)
+
+		var list []interface{}
+		for _, dependency := range dependencies {
+			list = append(list, dependency)
+		}
+		serviceData["depends_on"] = list
+	}
+
+	if healthCheck, ok := serviceData["healthcheck"].(map[interface{}]interface{}); ok {
+		if test, ok := healthCheck["test"].(string); ok {
+			healthCheck["test"] = []interface{}{"CMD-SHELL", test}
+		}
+	}
+
+	return serviceData
+}
+
+// longPort returns the short syntax ("[published:]target[/protocol]") of a
+// port in the long syntax.
+func longPort(port map[interface{}]interface{}) string {
+	short := fmt.Sprint(port["target"])
+	if published, ok := port["published"]; ok {
+		short = fmt.Sprintf("%v:%s", published, short)
+	}
+	if protocol := asString(port["protocol"]); protocol != "" && protocol != "tcp" {
+		short += "/" + protocol
+	}
+	return short
+}
+
+// longVolume returns the short syntax ("[source:]target[:ro]") of a bind or
+// volume mount in the long syntax.
+func longVolume(volume map[interface{}]interface{}) string {
+	short := asString(volume["target"])
+	if source := asString(volume["source"]); source != "" {
+		short = source + ":" + short
+	}
+	if readOnly, _ := volume["read_only"].(bool); readOnly {
+		short += ":ro"
+	}
+	return short
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	for version, expected := range map[string]string{
+		"":    "1",
+		"2":   "2.0",
+		"2.0": "2.0",
+		"2.1": "2.1",
+		"2.3": "2.1",
+		"3":   "3",
+		"3.0": "3",
+		"3.4": "3",
+	} {
+		schema, err := schemaVersion(version)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, schema, version)
+	}
+
+	for _, version := range []string{"1", "4", "3.x", "2.", "latest"} {
+		_, err := schemaVersion(version)
+		assert.NotNil(t, err, version)
+	}
+}
+
+func TestMergeV3(t *testing.T) {
+	version, config, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '3.3'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80"
+      - target: 443
+        published: 8443
+      - target: 53
+        protocol: udp
+    volumes:
+      - /data
+      - type: bind
+        source: ./static
+        target: /srv/static
+        read_only: true
+      - type: volume
+        target: /cache
+    healthcheck:
+      test: curl -f http://localhost
+      interval: 30s
+      retries: 3
+    secrets:
+      - db-password
+      - source: tls-key
+        target: server.key
+        mode: 0400
+    configs:
+      - nginx-conf
+    deploy:
+      mode: replicated
+      replicas: 0
+      resources:
+        limits:
+          cpus: '0.5'
+          memory: 512M
+      restart_policy:
+        condition: on-failure
+        max_attempts: 3
+      placement:
+        constraints:
+          - node.role == worker
+      update_config:
+        parallelism: 2
+        delay: 10s
+secrets:
+  db-password:
+    file: ./db-password.txt
+  tls-key:
+    external: true
+configs:
+  nginx-conf:
+    file: ./nginx.conf
+`), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "3.3", version)
+
+	web := config["web"]
+	assert.Equal(t, []string{"80", "8443:443", "53/udp"}, web.Ports)
+	assert.Equal(t, []string{"/data", "./static:/srv/static:ro", "/cache"}, web.Volumes)
+
+	assert.Equal(t, []string{"CMD-SHELL", "curl -f http://localhost"}, []string(web.HealthCheck.Test))
+	assert.Equal(t, "30s", web.HealthCheck.Interval)
+	assert.Equal(t, 3, web.HealthCheck.Retries)
+
+	mode := 0400
+	assert.Equal(t, []ServiceFile{
+		{Source: "db-password"},
+		{Source: "tls-key", Target: "server.key", Mode: &mode},
+	}, web.Secrets)
+	assert.Equal(t, []ServiceFile{{Source: "nginx-conf"}}, web.Configs)
+
+	replicas, maxAttempts, parallelism := 0, 3, 2
+	assert.Equal(t, DeployConfig{
+		Mode:     "replicated",
+		Replicas: &replicas,
+		Resources: Resources{
+			Limits: &Resource{CPUs: "0.5", Memory: "512M"},
+		},
+		RestartPolicy: &RestartPolicy{Condition: "on-failure", MaxAttempts: &maxAttempts},
+		Placement:     Placement{Constraints: []string{"node.role == worker"}},
+		UpdateConfig:  &UpdateConfig{Parallelism: &parallelism, Delay: "10s"},
+	}, web.Deploy)
+
+	assert.Equal(t, GetServiceHash("web", web), GetServiceHash("web", web))
+}
+
+func TestMergeV21DependsOnConditions(t *testing.T) {
+	_, config, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '2.1'
+services:
+  web:
+    image: nginx
+    depends_on:
+      db:
+        condition: service_healthy
+      cache:
+        condition: service_started
+  db:
+    image: postgres
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  cache:
+    image: redis
+`), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cache", "db"}, config["web"].DependsOn)
+	assert.Equal(t, []string{"CMD", "pg_isready"}, []string(config["db"].HealthCheck.Test))
+}
+
+func TestMergeInvalidVersions(t *testing.T) {
+	_, _, _, _, err := Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '4'
+services:
+  web:
+    image: nginx
+`), nil)
+	assert.NotNil(t, err)
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '3'
+services:
+  web:
+    image: nginx
+    volumes_from:
+      - data
+`), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'volumes_from'")
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '3'
+services:
+  web:
+    image: nginx
+    deploy:
+      mode: everywhere
+`), nil)
+	assert.NotNil(t, err)
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '2.1'
+services:
+  web:
+    image: nginx
+secrets:
+  password:
+    file: ./password.txt
+`), nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Unsupported top level key 'secrets'")
+
+	_, _, _, _, err = Merge(NewServiceConfigs(), nil, &NullLookup{}, "", []byte(`
+version: '3'
+services:
+  web:
+    command: nginx
+`), nil)
+	assert.NotNil(t, err)
+}
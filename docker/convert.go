@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/runconfig/opts"
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/network"
@@ -74,6 +75,49 @@ func volumes(c *config.ServiceConfig, ctx project.Context) map[string]struct{} {
 	return volumes
 }
 
+// fileBinds returns the binds mounting read-only the files of the secrets
+// and the configs of the service.
+func fileBinds(c *config.ServiceConfig, ctx project.Context) ([]string, error) {
+	var secrets, configs map[string]*config.FileConfig
+	if ctx.Project != nil {
+		secrets, configs = ctx.Project.Secrets, ctx.Project.Configs
+	}
+
+	binds, err := serviceFileBinds("secret", c.Secrets, secrets)
+	if err != nil {
+		return nil, err
+	}
+	configBinds, err := serviceFileBinds("config", c.Configs, configs)
+	if err != nil {
+		return nil, err
+	}
+	return append(binds, configBinds...), nil
+}
+
+// serviceFileBinds returns the binds of the specified secrets or configs.
+// External ones are only available to swarm services, they are ignored.
+func serviceFileBinds(kind string, files []config.ServiceFile, definitions map[string]*config.FileConfig) ([]string, error) {
+	var binds []string
+	for _, file := range files {
+		definition, ok := definitions[file.Source]
+		if !ok {
+			return nil, fmt.Errorf("Undefined %s %s", kind, file.Source)
+		}
+		if definition.External.External {
+			logrus.Warnf("The %s %s is external, external %ss are only available to swarm services", kind, file.Source, kind)
+			continue
+		}
+		if definition.File == "" {
+			return nil, fmt.Errorf("The %s %s has no file", kind, file.Source)
+		}
+		if file.UID != "" || file.GID != "" || file.Mode != nil {
+			logrus.Warnf("The uid, gid and mode of the %s %s are ignored, its file is bind mounted", kind, file.Source)
+		}
+		binds = append(binds, definition.File+":"+file.Path()+":ro")
+	}
+	return binds, nil
+}
+
 func restartPolicy(c *config.ServiceConfig) (*container.RestartPolicy, error) {
 	restart, err := opts.ParseRestartPolicy(c.Restart)
 	if err != nil {
@@ -130,6 +174,11 @@ func Convert(c *config.ServiceConfig, ctx project.Context) (*container.Config, *
 		return nil, nil, err
 	}
 
+	binds, err := fileBinds(c, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var volumesFrom []string
 	if c.VolumesFrom != nil {
 		volumesFrom, err = getVolumesFrom(c.VolumesFrom, ctx.Project.ServiceConfigs, ctx.ProjectName)
@@ -183,7 +232,7 @@ func Convert(c *config.ServiceConfig, ctx project.Context) (*container.Config, *
 		CapDrop:     strslice.StrSlice(utils.CopySlice(c.CapDrop)),
 		ExtraHosts:  utils.CopySlice(c.ExtraHosts),
 		Privileged:  c.Privileged,
-		Binds:       Filter(c.Volumes, isBind),
+		Binds:       append(Filter(c.Volumes, isBind), binds...),
 		DNS:         utils.CopySlice(c.DNS),
 		DNSSearch:   utils.CopySlice(c.DNSSearch),
 		LogConfig: container.LogConfig{
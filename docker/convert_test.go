@@ -6,6 +6,7 @@ import (
 
 	"github.com/docker/libcompose/config"
 	"github.com/docker/libcompose/lookup"
+	"github.com/docker/libcompose/project"
 	"github.com/docker/libcompose/yaml"
 	shlex "github.com/flynn/go-shlex"
 	"github.com/stretchr/testify/assert"
@@ -56,3 +57,30 @@ func TestParseLabels(t *testing.T) {
 	assert.Equal(t, yaml.Command{bashCmd}, sc.Entrypoint)
 	assert.Equal(t, []string{"less"}, []string(cfg.Entrypoint))
 }
+
+func TestParseSecretsAndConfigs(t *testing.T) {
+	ctx := &Context{}
+	ctx.ComposeFiles = []string{"foo/docker-compose.yml"}
+	ctx.ResourceLookup = &lookup.FileConfigLookup{}
+	p := project.NewProject(&ctx.Context, nil, nil)
+	p.Secrets["password"] = &config.FileConfig{File: "/srv/password.txt"}
+	p.Secrets["token"] = &config.FileConfig{External: yaml.External{External: true}}
+	p.Configs["nginx"] = &config.FileConfig{File: "/srv/nginx.conf"}
+
+	_, hostCfg, err := Convert(&config.ServiceConfig{
+		Volumes: []string{"/home:/home"},
+		Secrets: []config.ServiceFile{{Source: "password"}, {Source: "token"}},
+		Configs: []config.ServiceFile{{Source: "nginx", Target: "/etc/nginx/nginx.conf"}},
+	}, ctx.Context)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"/home:/home",
+		"/srv/password.txt:/run/secrets/password:ro",
+		"/srv/nginx.conf:/etc/nginx/nginx.conf:ro",
+	}, hostCfg.Binds)
+
+	_, _, err = Convert(&config.ServiceConfig{
+		Secrets: []config.ServiceFile{{Source: "undefined"}},
+	}, ctx.Context)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+	"github.com/docker/libcompose/version"
+)
+
+// Annotations set on every generated object, tracing it back to its compose source.
+const (
+	ProjectAnnotation = "kompose.io/project"
+	FilesAnnotation   = "kompose.io/compose-files"
+	ServiceAnnotation = "kompose.io/service"
+	VersionAnnotation = "kompose.io/version"
+	HashAnnotation    = "kompose.io/config-hash"
+
+	// GroupAnnotation lists the services whose containers run in the pods
+	// of a controller, when there are several.
+	GroupAnnotation = "kompose.io/services"
+)
+
+// Annotations returns the annotations of the objects generated for the
+// specified service of the project. The objects of a project are the ones
+// with its name, the compose files being traced by their absolute path.
+func Annotations(p *project.Project, name string, service *config.ServiceConfig) map[string]string {
+	return map[string]string{
+		ProjectAnnotation: p.Name,
+		FilesAnnotation:   strings.Join(absFiles(p.Files), ","),
+		ServiceAnnotation: name,
+		VersionAnnotation: version.VERSION,
+		HashAnnotation:    config.GetServiceHash(name, service),
+	}
+}
+
+// absFiles returns the absolute paths of the local files, the remote ones
+// and the standard input being left as is.
+func absFiles(files []string) []string {
+	var paths []string
+	for _, file := range files {
+		if file != "-" && file != "." && !strings.Contains(file, "://") && !strings.HasPrefix(file, "git@") {
+			if abs, err := filepath.Abs(file); err == nil {
+				file = abs
+			}
+		}
+		paths = append(paths, file)
+	}
+	return paths
+}
+
+// InProject returns whether an object was generated for a service of the
+// project with the specified name.
+func InProject(meta api.ObjectMeta, projectName string) bool {
+	_, ok := meta.Annotations[ServiceAnnotation]
+	return ok && meta.Annotations[ProjectAnnotation] == projectName
+}
+
+// Annotate adds the specified annotations to the metadata of the objects.
+func Annotate(annotations map[string]string, objects ...runtime.Object) error {
+	for _, obj := range objects {
+		meta, err := api.ObjectMetaFor(obj)
+		if err != nil {
+			return err
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			meta.Annotations[key] = value
+		}
+	}
+	return nil
+}
+
+// GroupHash returns the hash of the configurations of a group of services.
+func GroupHash(p *project.Project, names []string) string {
+	hash := sha1.New()
+	for _, name := range names {
+		service, ok := p.ServiceConfigs.Get(name)
+		if !ok {
+			return ""
+		}
+		io.WriteString(hash, config.GetServiceHash(name, service))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// UpToDate returns whether an object was generated from the current
+// configuration of the specified service. Objects without the hash annotation
+// were not generated by kompose and are never up to date.
+func UpToDate(meta api.ObjectMeta, name string, service *config.ServiceConfig) bool {
+	hash, ok := meta.Annotations[HashAnnotation]
+	return ok && hash == config.GetServiceHash(name, service)
+}
+
+// ObjectUpToDate returns whether an object was generated from the current
+// configuration of its service, or of the services of its group for the
+// controllers of a pod running several services.
+func ObjectUpToDate(p *project.Project, meta api.ObjectMeta) bool {
+	if group, ok := meta.Annotations[GroupAnnotation]; ok {
+		hash := GroupHash(p, strings.Split(group, ","))
+		return hash != "" && hash == meta.Annotations[HashAnnotation]
+	}
+	service, ok := p.ServiceConfigs.Get(meta.Annotations[ServiceAnnotation])
+	return ok && UpToDate(meta, meta.Annotations[ServiceAnnotation], service)
+}
@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/version"
+)
+
+func TestTransformAnnotations(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+`)
+	p.Name = "app"
+	p.Files = []string{"docker-compose.yml", "/srv/app/docker-compose.override.yml", "https://example.com/docker-compose.yml"}
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateRC: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(objects))
+
+	web, _ := p.ServiceConfigs.Get("web")
+	for _, obj := range objects {
+		meta, err := api.ObjectMetaFor(obj)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{
+			ProjectAnnotation: "app",
+			FilesAnnotation:   filepath.Join(dir, "docker-compose.yml") + ",/srv/app/docker-compose.override.yml,https://example.com/docker-compose.yml",
+			ServiceAnnotation: "web",
+			VersionAnnotation: version.VERSION,
+			HashAnnotation:    config.GetServiceHash("web", web),
+		}, meta.Annotations)
+		assert.True(t, UpToDate(*meta, "web", web))
+		assert.True(t, InProject(*meta, "app"))
+		assert.False(t, InProject(*meta, "other"))
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	service := &config.ServiceConfig{Image: "nginx"}
+	meta := api.ObjectMeta{
+		Annotations: map[string]string{HashAnnotation: config.GetServiceHash("web", service)},
+	}
+
+	assert.True(t, UpToDate(meta, "web", service))
+	assert.False(t, UpToDate(meta, "web", &config.ServiceConfig{Image: "nginx:1.11"}))
+	assert.False(t, UpToDate(api.ObjectMeta{}, "web", service))
+}
+
+func TestObjectUpToDate(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+  log:
+    image: fluentd
+    x-kompose-sidecar-of: web
+`)
+	web, _ := p.ServiceConfigs.Get("web")
+
+	meta := api.ObjectMeta{Annotations: map[string]string{
+		ServiceAnnotation: "web",
+		HashAnnotation:    config.GetServiceHash("web", web),
+	}}
+	assert.True(t, ObjectUpToDate(p, meta))
+
+	meta.Annotations[GroupAnnotation] = "web,log"
+	assert.False(t, ObjectUpToDate(p, meta))
+	meta.Annotations[HashAnnotation] = GroupHash(p, []string{"web", "log"})
+	assert.True(t, ObjectUpToDate(p, meta))
+
+	assert.False(t, ObjectUpToDate(p, api.ObjectMeta{Annotations: map[string]string{ServiceAnnotation: "db"}}))
+}
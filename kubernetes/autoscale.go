@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/config"
+)
+
+// Labels making kompose generate a HorizontalPodAutoscaler for a service: the
+// minimum and maximum number of replicas, and the target average CPU
+// utilization, in percent of the requested CPU.
+const (
+	AutoscaleMinLabel = "kompose.autoscale.min"
+	AutoscaleMaxLabel = "kompose.autoscale.max"
+	AutoscaleCPULabel = "kompose.autoscale.cpu"
+)
+
+// Autoscaled returns whether any autoscale label is set on the service.
+func Autoscaled(service *config.ServiceConfig) bool {
+	for _, label := range []string{AutoscaleMinLabel, AutoscaleMaxLabel, AutoscaleCPULabel} {
+		if _, ok := service.Labels[label]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HorizontalPodAutoscaler creates a HorizontalPodAutoscaler scaling the
+// target controller of the specified service, as set by its autoscale labels.
+// The vendored autoscaling group only holds the autoscaling/v1 external
+// types, the internal type is served by the extensions group.
+func HorizontalPodAutoscaler(name string, service *config.ServiceConfig, target runtime.Object) (*extensions.HorizontalPodAutoscaler, error) {
+	maxLabel, ok := service.Labels[AutoscaleMaxLabel]
+	if !ok {
+		return nil, fmt.Errorf("Label %s must be set to autoscale service %s", AutoscaleMaxLabel, name)
+	}
+	max, err := strconv.Atoi(maxLabel)
+	if err != nil || max < 1 {
+		return nil, fmt.Errorf("Invalid maximum number of replicas %s for service %s", maxLabel, name)
+	}
+
+	spec := extensions.HorizontalPodAutoscalerSpec{
+		ScaleRef: extensions.SubresourceReference{
+			Kind:        Kind(target),
+			Name:        Name(target),
+			APIVersion:  target.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+			Subresource: "scale",
+		},
+		MaxReplicas: max,
+	}
+
+	if minLabel, ok := service.Labels[AutoscaleMinLabel]; ok {
+		min, err := strconv.Atoi(minLabel)
+		if err != nil || min < 1 || min > max {
+			return nil, fmt.Errorf("Invalid minimum number of replicas %s for service %s, it must be between 1 and %d", minLabel, name, max)
+		}
+		spec.MinReplicas = &min
+	}
+
+	if cpuLabel, ok := service.Labels[AutoscaleCPULabel]; ok {
+		cpu, err := strconv.Atoi(strings.TrimSuffix(cpuLabel, "%"))
+		if err != nil || cpu < 1 {
+			return nil, fmt.Errorf("Invalid target CPU utilization %s for service %s", cpuLabel, name)
+		}
+		spec.CPUUtilization = &extensions.CPUTargetUtilization{TargetPercentage: cpu}
+	}
+
+	return &extensions.HorizontalPodAutoscaler{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "extensions/v1beta1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: spec,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestTransformAutoscaler(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    labels:
+      kompose.autoscale.min: "2"
+      kompose.autoscale.max: "10"
+      kompose.autoscale.cpu: "75%"
+  db:
+    image: postgres
+`)
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateRC: true, CreateDeployment: true})
+	assert.Nil(t, err)
+
+	kinds := []string{}
+	for _, obj := range objects {
+		kinds = append(kinds, Kind(obj))
+	}
+	assert.Equal(t, []string{"ReplicationController", "Deployment", "ReplicationController", "Deployment", "HorizontalPodAutoscaler"}, kinds)
+
+	hpa := objects[4].(*extensions.HorizontalPodAutoscaler)
+	assert.Equal(t, "web", hpa.Name)
+	assert.Equal(t, extensions.SubresourceReference{
+		Kind:        "Deployment",
+		Name:        "web",
+		APIVersion:  "extensions/v1beta1",
+		Subresource: "scale",
+	}, hpa.Spec.ScaleRef)
+	assert.Equal(t, 2, *hpa.Spec.MinReplicas)
+	assert.Equal(t, 10, hpa.Spec.MaxReplicas)
+	assert.Equal(t, 75, hpa.Spec.CPUUtilization.TargetPercentage)
+	assert.Equal(t, map[string]string{"service": "web"}, hpa.Labels)
+	assert.Equal(t, "web", hpa.Annotations[ServiceAnnotation])
+
+	objects, err = (&Converter{}).Transform(p, ConvertOptions{CreateRC: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "ReplicationController", objects[2].(*extensions.HorizontalPodAutoscaler).Spec.ScaleRef.Kind)
+
+	_, err = (&Converter{}).Transform(p, ConvertOptions{CreateDaemonSet: true})
+	assert.NotNil(t, err)
+}
+
+func TestHorizontalPodAutoscalerDefaults(t *testing.T) {
+	service := &config.ServiceConfig{
+		Image:  "nginx",
+		Labels: map[string]string{AutoscaleMaxLabel: "4"},
+	}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+
+	hpa, err := HorizontalPodAutoscaler("web", service, ReplicaSet("web", service, template))
+	assert.Nil(t, err)
+	assert.Equal(t, "ReplicaSet", hpa.Spec.ScaleRef.Kind)
+	assert.Nil(t, hpa.Spec.MinReplicas)
+	assert.Equal(t, 4, hpa.Spec.MaxReplicas)
+	assert.Nil(t, hpa.Spec.CPUUtilization)
+}
+
+func TestHorizontalPodAutoscalerInvalid(t *testing.T) {
+	for _, labels := range []map[string]string{
+		{AutoscaleMinLabel: "2"},
+		{AutoscaleMaxLabel: "0"},
+		{AutoscaleMaxLabel: "3", AutoscaleMinLabel: "4"},
+		{AutoscaleMaxLabel: "3", AutoscaleMinLabel: "zero"},
+		{AutoscaleMaxLabel: "3", AutoscaleCPULabel: "high"},
+	} {
+		service := &config.ServiceConfig{Image: "nginx", Labels: labels}
+		template, err := PodTemplate("web", service)
+		assert.Nil(t, err)
+
+		_, err = HorizontalPodAutoscaler("web", service, Deployment("web", service, template))
+		assert.NotNil(t, err, "labels %v", labels)
+	}
+}
@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// Kinds lists the kinds of objects the Kubernetes conversion generates and
+// that can be fetched from the API server.
+var Kinds = []string{"Secret", "ConfigMap", "Service", "ReplicationController", "Deployment", "DaemonSet", "ReplicaSet", "HorizontalPodAutoscaler"}
+
+// Get fetches the object of the specified kind and name from the API server.
+func Get(c *client.Client, namespace, kind, name string) (runtime.Object, error) {
+	switch kind {
+	case "Secret":
+		return c.Secrets(namespace).Get(name)
+	case "ConfigMap":
+		return c.ConfigMaps(namespace).Get(name)
+	case "Service":
+		return c.Services(namespace).Get(name)
+	case "ReplicationController":
+		return c.ReplicationControllers(namespace).Get(name)
+	case "Deployment":
+		return c.Extensions().Deployments(namespace).Get(name)
+	case "DaemonSet":
+		return c.Extensions().DaemonSets(namespace).Get(name)
+	case "ReplicaSet":
+		return c.Extensions().ReplicaSets(namespace).Get(name)
+	case "HorizontalPodAutoscaler":
+		return c.Extensions().HorizontalPodAutoscalers(namespace).Get(name)
+	}
+	return nil, fmt.Errorf("Unsupported kind %s", kind)
+}
+
+// Create creates the specified object on the API server.
+func Create(c *client.Client, namespace string, obj runtime.Object) (runtime.Object, error) {
+	switch o := obj.(type) {
+	case *api.Secret:
+		return c.Secrets(namespace).Create(o)
+	case *api.ConfigMap:
+		return c.ConfigMaps(namespace).Create(o)
+	case *api.Service:
+		return c.Services(namespace).Create(o)
+	case *api.ReplicationController:
+		return c.ReplicationControllers(namespace).Create(o)
+	case *extensions.Deployment:
+		return c.Extensions().Deployments(namespace).Create(o)
+	case *extensions.DaemonSet:
+		return c.Extensions().DaemonSets(namespace).Create(o)
+	case *extensions.ReplicaSet:
+		return c.Extensions().ReplicaSets(namespace).Create(o)
+	case *extensions.HorizontalPodAutoscaler:
+		return c.Extensions().HorizontalPodAutoscalers(namespace).Create(o)
+	}
+	return nil, fmt.Errorf("Unsupported kind %s", Kind(obj))
+}
+
+// Update replaces the specified object on the API server.
+func Update(c *client.Client, namespace string, obj runtime.Object) (runtime.Object, error) {
+	switch o := obj.(type) {
+	case *api.Secret:
+		return c.Secrets(namespace).Update(o)
+	case *api.ConfigMap:
+		return c.ConfigMaps(namespace).Update(o)
+	case *api.Service:
+		return c.Services(namespace).Update(o)
+	case *api.ReplicationController:
+		return c.ReplicationControllers(namespace).Update(o)
+	case *extensions.Deployment:
+		return c.Extensions().Deployments(namespace).Update(o)
+	case *extensions.DaemonSet:
+		return c.Extensions().DaemonSets(namespace).Update(o)
+	case *extensions.ReplicaSet:
+		return c.Extensions().ReplicaSets(namespace).Update(o)
+	case *extensions.HorizontalPodAutoscaler:
+		return c.Extensions().HorizontalPodAutoscalers(namespace).Update(o)
+	}
+	return nil, fmt.Errorf("Unsupported kind %s", Kind(obj))
+}
+
+// Delete deletes the object of the specified kind and name from the API server.
+func Delete(c *client.Client, namespace, kind, name string) error {
+	switch kind {
+	case "Secret":
+		return c.Secrets(namespace).Delete(name)
+	case "ConfigMap":
+		return c.ConfigMaps(namespace).Delete(name)
+	case "Service":
+		return c.Services(namespace).Delete(name)
+	case "ReplicationController":
+		return c.ReplicationControllers(namespace).Delete(name)
+	case "Deployment":
+		return c.Extensions().Deployments(namespace).Delete(name, nil)
+	case "DaemonSet":
+		return c.Extensions().DaemonSets(namespace).Delete(name)
+	case "ReplicaSet":
+		return c.Extensions().ReplicaSets(namespace).Delete(name, nil)
+	case "HorizontalPodAutoscaler":
+		return c.Extensions().HorizontalPodAutoscalers(namespace).Delete(name, nil)
+	}
+	return fmt.Errorf("Unsupported kind %s", kind)
+}
+
+// List fetches the objects of the specified kind that were generated by
+// kompose, that is the ones carrying its service annotation.
+func List(c *client.Client, namespace, kind string) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+	opts := api.ListOptions{}
+
+	switch kind {
+	case "Secret":
+		list, err := c.Secrets(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "ConfigMap":
+		list, err := c.ConfigMaps(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Service":
+		list, err := c.Services(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "ReplicationController":
+		list, err := c.ReplicationControllers(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "Deployment":
+		list, err := c.Extensions().Deployments(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "DaemonSet":
+		list, err := c.Extensions().DaemonSets(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "ReplicaSet":
+		list, err := c.Extensions().ReplicaSets(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	case "HorizontalPodAutoscaler":
+		list, err := c.Extensions().HorizontalPodAutoscalers(namespace).List(opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported kind %s", kind)
+	}
+
+	generated := []runtime.Object{}
+	for _, obj := range objects {
+		meta, err := api.ObjectMetaFor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := meta.Annotations[ServiceAnnotation]; ok {
+			generated = append(generated, obj)
+		}
+	}
+	return generated, nil
+}
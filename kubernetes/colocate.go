@@ -0,0 +1,451 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+)
+
+// Extension keys declaring a service as a sidecar or an init container of the
+// pod of another service.
+const (
+	SidecarOfExtension = "x-kompose-sidecar-of"
+	InitOfExtension    = "x-kompose-init-of"
+)
+
+// Share describes a service joining a namespace, or mounting the volumes, of
+// another service, or declared as a sidecar or an init container of its pod
+// with the x-kompose-sidecar-of and x-kompose-init-of keys. Containers can
+// only share them within a pod.
+type Share struct {
+	Service string
+	Target  string
+	// Kind is network, ipc, pid, volumes, sidecar or init.
+	Kind     string
+	ReadOnly bool
+}
+
+func (s Share) String() string {
+	switch s.Kind {
+	case "volumes":
+		return fmt.Sprintf("%s mounts the volumes of %s", s.Service, s.Target)
+	case "sidecar":
+		return fmt.Sprintf("%s is a sidecar of %s", s.Service, s.Target)
+	case "init":
+		return fmt.Sprintf("%s is an init container of %s", s.Service, s.Target)
+	}
+	return fmt.Sprintf("%s joins the %s namespace of %s", s.Service, s.Kind, s.Target)
+}
+
+// Group holds services whose containers run in the same pod, named after
+// the first service, which does not join the others.
+type Group struct {
+	Name     string
+	Services []string
+	Shares   []Share
+}
+
+func (g Group) String() string {
+	shares := []string{}
+	for _, share := range g.Shares {
+		shares = append(shares, share.String())
+	}
+	return fmt.Sprintf("Services %s run in the %s pod: %s", strings.Join(g.Services, ", "), g.Name, strings.Join(shares, ", "))
+}
+
+// Shares returns the namespaces and volumes the specified service shares
+// with other services of the project.
+func Shares(p *project.Project, name string, service *config.ServiceConfig) ([]Share, error) {
+	shares := []Share{}
+
+	sidecarOf, err := extensionString(name, service, SidecarOfExtension)
+	if err != nil {
+		return nil, err
+	}
+	initOf, err := extensionString(name, service, InitOfExtension)
+	if err != nil {
+		return nil, err
+	}
+	if sidecarOf != "" && initOf != "" {
+		return nil, fmt.Errorf("Service %s cannot be both a sidecar and an init container", name)
+	}
+	for _, pod := range []struct{ kind, target string }{
+		{"sidecar", sidecarOf},
+		{"init", initOf},
+	} {
+		if pod.target == "" {
+			continue
+		}
+		if !p.ServiceConfigs.Has(pod.target) || pod.target == name {
+			return nil, fmt.Errorf("Service %s joins the pod of %s with x-kompose-%s-of, which is not another service of the project", name, pod.target, pod.kind)
+		}
+		shares = append(shares, Share{Service: name, Target: pod.target, Kind: pod.kind})
+	}
+
+	for _, namespace := range []struct{ kind, mode string }{
+		{"network", service.NetworkMode},
+		{"ipc", service.Ipc},
+		{"pid", service.Pid},
+	} {
+		if target := sharedService(p, namespace.mode); target != "" && target != name {
+			shares = append(shares, Share{Service: name, Target: target, Kind: namespace.kind})
+		}
+	}
+
+	for _, volumesFrom := range service.VolumesFrom {
+		target := strings.TrimPrefix(volumesFrom, "service:")
+		readOnly := false
+		if i := strings.LastIndex(target, ":"); i >= 0 && (target[i+1:] == "ro" || target[i+1:] == "rw") {
+			readOnly = target[i+1:] == "ro"
+			target = target[:i]
+		}
+		if p.ServiceConfigs.Has(target) && target != name {
+			shares = append(shares, Share{Service: name, Target: target, Kind: "volumes", ReadOnly: readOnly})
+		}
+	}
+	return shares, nil
+}
+
+// extensionString returns the string value of an extension key of the
+// service, empty if it is not set.
+func extensionString(name string, service *config.ServiceConfig, key string) (string, error) {
+	var value string
+	if _, err := config.Extension(service.Extensions, key, &value); err != nil {
+		return "", fmt.Errorf("Invalid %s for service %s: %v", key, name, err)
+	}
+	return value, nil
+}
+
+// initOf returns the service whose pod the specified service joins as an init
+// container, empty if it is not an init container.
+func initOf(service *config.ServiceConfig) string {
+	target, _ := service.Extensions[InitOfExtension].(string)
+	return target
+}
+
+// sharedService returns the service referenced by a "service:name" or
+// "container:name" namespace mode, if it is part of the project.
+func sharedService(p *project.Project, mode string) string {
+	for _, prefix := range []string{"service:", "container:"} {
+		if strings.HasPrefix(mode, prefix) && p.ServiceConfigs.Has(mode[len(prefix):]) {
+			return mode[len(prefix):]
+		}
+	}
+	return ""
+}
+
+// Groups returns the services of the project grouped by pod, sorted by name.
+// Most groups hold a single service.
+func Groups(p *project.Project) ([]Group, error) {
+	names := ServiceNames(p)
+
+	// union-find of the services sharing something
+	parents := map[string]string{}
+	var find func(string) string
+	find = func(name string) string {
+		if parent, ok := parents[name]; ok && parent != name {
+			root := find(parent)
+			parents[name] = root
+			return root
+		}
+		return name
+	}
+
+	shares := map[string][]Share{}
+	joins := map[string]bool{}
+	inits := map[string]bool{}
+	for _, name := range names {
+		serviceConfig, _ := p.ServiceConfigs.Get(name)
+		serviceShares, err := Shares(p, name, serviceConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, share := range serviceShares {
+			inits[name] = inits[name] || share.Kind == "init"
+			shares[name] = append(shares[name], share)
+			joins[name] = true
+			if a, b := find(name), find(share.Target); a != b {
+				parents[a] = b
+			}
+		}
+	}
+
+	members := map[string][]string{}
+	roots := []string{}
+	for _, name := range names {
+		root := find(name)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], name)
+	}
+
+	groups := []Group{}
+	for _, root := range roots {
+		services := members[root]
+		// the pod is named after the first service not joining another one
+		first := 0
+		for i, name := range services {
+			if !joins[name] {
+				first = i
+				break
+			}
+		}
+		services = append([]string{services[first]}, append(append([]string{}, services[:first]...), services[first+1:]...)...)
+
+		group := Group{Name: services[0], Services: services}
+		for _, name := range services {
+			for _, share := range shares[name] {
+				if inits[share.Target] {
+					return nil, fmt.Errorf("Service %s cannot share %s with %s, an init container", name, share.Kind, share.Target)
+				}
+			}
+			group.Shares = append(group.Shares, shares[name]...)
+		}
+		groups = append(groups, group)
+	}
+
+	sort.Sort(groupsByName(groups))
+	return groups, nil
+}
+
+// ServiceGroup returns the group of the specified service, a group of its
+// own if it is not an enabled service of the project.
+func ServiceGroup(p *project.Project, name string) (Group, error) {
+	groups, err := Groups(p)
+	if err != nil {
+		return Group{}, err
+	}
+	for _, group := range groups {
+		for _, member := range group.Services {
+			if member == name {
+				return group, nil
+			}
+		}
+	}
+	return Group{Name: name, Services: []string{name}}, nil
+}
+
+type groupsByName []Group
+
+func (g groupsByName) Len() int           { return len(g) }
+func (g groupsByName) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g groupsByName) Less(i, j int) bool { return g[i].Name < g[j].Name }
+
+// GroupObjects generates the objects of a group of services: the objects of
+// its service if it holds one, otherwise the Secret and ConfigMap of each
+// service, a Service for each service with ports and the controllers of a
+// pod running the containers of every service.
+// The controllers are configured by the labels of the first service.
+func GroupObjects(p *project.Project, group Group, opts ConvertOptions) ([]runtime.Object, error) {
+	serviceConfig, _ := p.ServiceConfigs.Get(group.Name)
+	if len(group.Services) == 1 {
+		return ServiceObjects(p, group.Name, serviceConfig, opts)
+	}
+
+	objects := []runtime.Object{}
+	for _, name := range group.Services {
+		member, _ := p.ServiceConfigs.Get(name)
+		files, err := fileObjects(p, name, member)
+		if err != nil {
+			return nil, err
+		}
+		if err := Annotate(Annotations(p, name, member), files...); err != nil {
+			return nil, err
+		}
+		objects = append(objects, files...)
+	}
+
+	for _, name := range group.Services {
+		member, _ := p.ServiceConfigs.Get(name)
+		svc, err := Service(name, member)
+		if err != nil {
+			return nil, err
+		}
+		if len(svc.Spec.Ports) == 0 {
+			continue
+		}
+		if initOf(member) != "" {
+			logrus.Warnf("Service %s is an init container, its ports are not exposed", name)
+			continue
+		}
+		svc.Spec.Selector = map[string]string{"service": group.Name}
+		if err := Annotate(Annotations(p, name, member), svc); err != nil {
+			return nil, err
+		}
+		objects = append(objects, svc)
+	}
+
+	template, err := GroupPodTemplate(p, group)
+	if err != nil {
+		return nil, err
+	}
+
+	controllers, err := controllerObjects(group.Name, serviceConfig, template, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := Annotations(p, group.Name, serviceConfig)
+	annotations[GroupAnnotation] = strings.Join(group.Services, ",")
+	annotations[HashAnnotation] = GroupHash(p, group.Services)
+	if err := Annotate(annotations, controllers...); err != nil {
+		return nil, err
+	}
+	return append(objects, controllers...), nil
+}
+
+// GroupPodTemplate merges the pod templates of the services of a group. The
+// services mounting the volumes of another one mount its volumes at the same
+// paths, its anonymous volumes becoming emptyDir volumes. The containers of
+// the init services run as init containers, in the order of the group.
+func GroupPodTemplate(p *project.Project, group Group) (api.PodTemplateSpec, error) {
+	serviceConfig, _ := p.ServiceConfigs.Get(group.Name)
+	template, err := servicePodTemplate(p, group.Name, serviceConfig)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+
+	if initOf(serviceConfig) != "" {
+		return api.PodTemplateSpec{}, fmt.Errorf("Service %s is an init container of %s but runs the %s pod", group.Name, initOf(serviceConfig), group.Name)
+	}
+
+	initContainers := []v1.Container{}
+	containers := map[string]int{group.Name: 0}
+	for _, name := range group.Services {
+		member, _ := p.ServiceConfigs.Get(name)
+		memberTemplate := template
+		if name != group.Name {
+			if memberTemplate, err = servicePodTemplate(p, name, member); err != nil {
+				return api.PodTemplateSpec{}, err
+			}
+			if initOf(member) == "" && memberTemplate.Spec.RestartPolicy != template.Spec.RestartPolicy {
+				return api.PodTemplateSpec{}, fmt.Errorf("Services %s and %s share a pod but have different restart policies", group.Name, name)
+			}
+			if initOf(member) == "" {
+				containers[name] = len(template.Spec.Containers)
+				template.Spec.Containers = append(template.Spec.Containers, memberTemplate.Spec.Containers...)
+			}
+			template.Spec.Volumes = append(template.Spec.Volumes, memberTemplate.Spec.Volumes...)
+
+			var conflicts []string
+			template.Spec.NodeSelector, conflicts = mergeNodeSelectors(template.Spec.NodeSelector, memberTemplate.Spec.NodeSelector)
+			if len(conflicts) > 0 {
+				return api.PodTemplateSpec{}, fmt.Errorf("Services %s and %s share a pod but have conflicting placement constraints on %s", group.Name, name, strings.Join(conflicts, ", "))
+			}
+			if data, ok := memberTemplate.Annotations[api.AffinityAnnotationKey]; ok {
+				affinity, err := mergeAffinities(template.Annotations[api.AffinityAnnotationKey], data)
+				if err != nil {
+					return api.PodTemplateSpec{}, err
+				}
+				if template.Annotations == nil {
+					template.Annotations = map[string]string{}
+				}
+				template.Annotations[api.AffinityAnnotationKey] = affinity
+			}
+			for key, value := range memberTemplate.Annotations {
+				if template.Annotations == nil {
+					template.Annotations = map[string]string{}
+				}
+				if _, ok := template.Annotations[key]; !ok {
+					template.Annotations[key] = value
+				}
+			}
+		}
+
+		if data, ok := memberTemplate.Annotations[InitContainersBetaAnnotation]; ok {
+			memberInitContainers := []v1.Container{}
+			if err := json.Unmarshal([]byte(data), &memberInitContainers); err != nil {
+				return api.PodTemplateSpec{}, err
+			}
+			initContainers = append(initContainers, memberInitContainers...)
+		}
+
+		if initOf(member) != "" {
+			initContainer := v1.Container{}
+			if err := api.Scheme.Convert(&memberTemplate.Spec.Containers[0], &initContainer); err != nil {
+				return api.PodTemplateSpec{}, err
+			}
+			initContainers = append(initContainers, initContainer)
+		}
+	}
+
+	if len(initContainers) > 0 {
+		data, err := json.Marshal(initContainers)
+		if err != nil {
+			return api.PodTemplateSpec{}, err
+		}
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[InitContainersAlphaAnnotation] = string(data)
+		template.Annotations[InitContainersBetaAnnotation] = string(data)
+	}
+
+	// the anonymous volumes of the services whose volumes are mounted by
+	// others are shared through emptyDir volumes
+	shared := map[string]bool{}
+	for _, share := range group.Shares {
+		if share.Kind == "pid" {
+			logrus.Warnf("The containers of a pod do not share their pid namespace, %s and %s only share the pod", share.Service, share.Target)
+		}
+		if share.Kind != "volumes" || shared[share.Target] {
+			continue
+		}
+		shared[share.Target] = true
+
+		target, _ := p.ServiceConfigs.Get(share.Target)
+		container := &template.Spec.Containers[containers[share.Target]]
+		for _, volume := range target.Volumes {
+			parts := strings.Split(volume, ":")
+			if len(parts) != 1 {
+				continue
+			}
+			volumeName := fmt.Sprintf("%s-shared%d", share.Target, len(container.VolumeMounts))
+			container.VolumeMounts = append(container.VolumeMounts, api.VolumeMount{
+				Name:      volumeName,
+				MountPath: strings.TrimSpace(parts[0]),
+			})
+			template.Spec.Volumes = append(template.Spec.Volumes, api.Volume{
+				Name: volumeName,
+				VolumeSource: api.VolumeSource{
+					EmptyDir: &api.EmptyDirVolumeSource{},
+				},
+			})
+		}
+	}
+
+	for _, share := range group.Shares {
+		if share.Kind != "volumes" {
+			continue
+		}
+		index, ok := containers[share.Service]
+		if !ok {
+			logrus.Warnf("Service %s is an init container, the volumes of %s are not mounted", share.Service, share.Target)
+			continue
+		}
+		container := &template.Spec.Containers[index]
+		mounted := map[string]bool{}
+		for _, mount := range container.VolumeMounts {
+			mounted[mount.MountPath] = true
+		}
+		for _, mount := range template.Spec.Containers[containers[share.Target]].VolumeMounts {
+			if mounted[mount.MountPath] {
+				continue
+			}
+			mount.ReadOnly = mount.ReadOnly || share.ReadOnly
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
+
+	return template, nil
+}
@@ -0,0 +1,182 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+const colocatedComposeFile = `
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80"
+    volumes:
+      - /var/www
+      - /srv:/srv
+  proxy:
+    image: haproxy
+    network_mode: service:web
+    ports:
+      - "443"
+  backup:
+    image: busybox
+    volumes_from:
+      - web:ro
+  db:
+    image: postgres
+`
+
+func TestGroups(t *testing.T) {
+	p := newTestProject(t, colocatedComposeFile)
+
+	groups, err := Groups(p)
+	assert.Nil(t, err)
+	assert.Equal(t, []Group{
+		{Name: "db", Services: []string{"db"}},
+		{
+			Name:     "web",
+			Services: []string{"web", "backup", "proxy"},
+			Shares: []Share{
+				{Service: "backup", Target: "web", Kind: "volumes", ReadOnly: true},
+				{Service: "proxy", Target: "web", Kind: "network"},
+			},
+		},
+	}, groups)
+	assert.Equal(t, "Services web, backup, proxy run in the web pod: backup mounts the volumes of web, proxy joins the network namespace of web", groups[1].String())
+}
+
+func TestGroupPodTemplate(t *testing.T) {
+	p := newTestProject(t, colocatedComposeFile)
+
+	groups, err := Groups(p)
+	assert.Nil(t, err)
+	template, err := GroupPodTemplate(p, groups[1])
+	assert.Nil(t, err)
+
+	assert.Equal(t, "web", template.Labels["service"])
+	assert.Len(t, template.Spec.Containers, 3)
+
+	web := template.Spec.Containers[0]
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, []api.VolumeMount{
+		{Name: "web-volume0", MountPath: "/srv", ReadOnly: true},
+		{Name: "web-shared1", MountPath: "/var/www"},
+	}, web.VolumeMounts)
+
+	backup := template.Spec.Containers[1]
+	assert.Equal(t, "backup", backup.Name)
+	assert.Equal(t, []api.VolumeMount{
+		{Name: "web-volume0", MountPath: "/srv", ReadOnly: true},
+		{Name: "web-shared1", MountPath: "/var/www", ReadOnly: true},
+	}, backup.VolumeMounts)
+
+	assert.Equal(t, "proxy", template.Spec.Containers[2].Name)
+	assert.NotNil(t, template.Spec.Volumes[len(template.Spec.Volumes)-1].EmptyDir)
+}
+
+func TestTransformGroups(t *testing.T) {
+	p := newTestProject(t, colocatedComposeFile)
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateDeployment: true})
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, obj := range objects {
+		names = append(names, Kind(obj)+"/"+Name(obj))
+	}
+	assert.Equal(t, []string{"Deployment/db", "Service/web", "Service/proxy", "Deployment/web"}, names)
+
+	proxy := objects[2].(*api.Service)
+	assert.Equal(t, map[string]string{"service": "web"}, proxy.Spec.Selector)
+	assert.Equal(t, "proxy", proxy.Annotations[ServiceAnnotation])
+
+	deployment := objects[3].(*extensions.Deployment)
+	assert.Equal(t, "web,backup,proxy", deployment.Annotations[GroupAnnotation])
+	assert.Equal(t, GroupHash(p, []string{"web", "backup", "proxy"}), deployment.Annotations[HashAnnotation])
+	assert.Equal(t, "yes", upToDate(p, deployment))
+}
+
+func TestGroupPodTemplateSidecars(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+  proxy:
+    image: haproxy
+    x-kompose-sidecar-of: web
+  migrate:
+    image: flyway
+    command: migrate
+    x-kompose-init-of: web
+`)
+
+	groups, err := Groups(p)
+	assert.Nil(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, []string{"web", "migrate", "proxy"}, groups[0].Services)
+
+	template, err := GroupPodTemplate(p, groups[0])
+	assert.Nil(t, err)
+	assert.Len(t, template.Spec.Containers, 2)
+	assert.Equal(t, "proxy", template.Spec.Containers[1].Name)
+
+	initContainers := []v1.Container{}
+	assert.Nil(t, json.Unmarshal([]byte(template.Annotations[InitContainersBetaAnnotation]), &initContainers))
+	assert.Len(t, initContainers, 1)
+	assert.Equal(t, "migrate", initContainers[0].Name)
+	assert.Equal(t, "flyway", initContainers[0].Image)
+	assert.Equal(t, []string{"migrate"}, initContainers[0].Command)
+}
+
+func TestGroupPodTemplateAffinity(t *testing.T) {
+	p := newTestProject(t, `
+version: '3'
+services:
+  web:
+    image: nginx
+    deploy:
+      placement:
+        constraints:
+          - node.hostname != build
+  proxy:
+    image: haproxy
+    x-kompose-sidecar-of: web
+    deploy:
+      placement:
+        constraints:
+          - node.labels.disk != hdd
+`)
+
+	groups, err := Groups(p)
+	assert.Nil(t, err)
+	template, err := GroupPodTemplate(p, groups[0])
+	assert.Nil(t, err)
+
+	affinity := api.Affinity{}
+	assert.Nil(t, json.Unmarshal([]byte(template.Annotations[api.AffinityAnnotationKey]), &affinity))
+	assert.Equal(t, []api.NodeSelectorTerm{{MatchExpressions: []api.NodeSelectorRequirement{
+		{Key: "kubernetes.io/hostname", Operator: api.NodeSelectorOpNotIn, Values: []string{"build"}},
+		{Key: "disk", Operator: api.NodeSelectorOpNotIn, Values: []string{"hdd"}},
+	}}}, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+}
+
+func TestGroupsInvalidSidecar(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  proxy:
+    image: haproxy
+    x-kompose-sidecar-of: web
+`)
+
+	_, err := Groups(p)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/docker/libcompose/project"
+)
+
+// Controller is a project.Container implementation. The containers of a
+// Kubernetes service are the controllers running its pods, they are stopped
+// once scaled to zero replicas.
+type Controller struct {
+	Kind     string
+	name     string
+	replicas int
+}
+
+// ID returns the kind and name of the controller.
+func (c *Controller) ID() (string, error) {
+	return c.Kind + "/" + c.name, nil
+}
+
+// Name returns the name of the controller.
+func (c *Controller) Name() string {
+	return c.name
+}
+
+// Port is not supported, the ports of a service are published by its Kubernetes Service.
+func (c *Controller) Port(ctx context.Context, port string) (string, error) {
+	return "", project.ErrUnsupported
+}
+
+// IsRunning returns whether the controller has any replicas.
+func (c *Controller) IsRunning(ctx context.Context) (bool, error) {
+	return c.replicas > 0, nil
+}
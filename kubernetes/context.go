@@ -0,0 +1,21 @@
+package kubernetes
+
+import (
+	"k8s.io/kubernetes/pkg/client/restclient"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/project"
+)
+
+// Context holds context meta information about a libcompose project and the
+// Kubernetes cluster it runs on.
+type Context struct {
+	project.Context
+	Client    *client.Client
+	Namespace string
+	// Config is the configuration of the client, which is created from it
+	// when it is not set. It attaches the standard streams of one-off pods.
+	Config *restclient.Config
+	// ConvertOptions selects the objects generated for each service.
+	ConvertOptions ConvertOptions
+}
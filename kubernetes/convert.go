@@ -0,0 +1,356 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/docker/libcompose/config"
+)
+
+// KomposeLabelPrefix prefixes the service labels configuring the conversion,
+// they are not copied to the generated objects.
+const KomposeLabelPrefix = "kompose."
+
+// Labels returns the labels set on every object generated for the specified service.
+func Labels(name string, service *config.ServiceConfig) map[string]string {
+	labels := map[string]string{"service": name}
+	for key, value := range service.Labels {
+		if strings.HasPrefix(key, KomposeLabelPrefix) {
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
+// PodTemplate converts a service configuration into the pod template shared
+// by every controller generated for that service.
+func PodTemplate(name string, service *config.ServiceConfig) (api.PodTemplateSpec, error) {
+	container := api.Container{
+		Name:       name,
+		Image:      service.Image,
+		Command:    service.Command,
+		WorkingDir: service.WorkingDir,
+	}
+
+	envs, err := envVars(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+	container.Env = envs
+
+	ports, err := containerPorts(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+	container.Ports = ports
+
+	volumeMounts, volumes := volumes(name, service)
+	container.VolumeMounts = volumeMounts
+
+	resources, err := resources(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+	container.Resources = resources
+
+	if service.Privileged {
+		privileged := service.Privileged
+		container.SecurityContext = &api.SecurityContext{
+			Privileged: &privileged,
+		}
+	}
+
+	restartPolicy, err := restartPolicy(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+
+	annotations, err := podAnnotations(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+
+	nodeSelector, affinity, err := placement(name, service)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+	if affinity != nil {
+		data, err := affinityAnnotation(affinity)
+		if err != nil {
+			return api.PodTemplateSpec{}, err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[api.AffinityAnnotationKey] = data
+	}
+
+	return api.PodTemplateSpec{
+		ObjectMeta: api.ObjectMeta{
+			Labels:      Labels(name, service),
+			Annotations: annotations,
+		},
+		Spec: api.PodSpec{
+			Containers:    []api.Container{container},
+			Volumes:       volumes,
+			RestartPolicy: restartPolicy,
+			NodeSelector:  nodeSelector,
+		},
+	}, nil
+}
+
+// Service converts a service configuration into a Kubernetes Service exposing its ports.
+func Service(name string, service *config.ServiceConfig) (*api.Service, error) {
+	ports, err := servicePorts(name, service)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Service{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: api.ServiceSpec{
+			Selector: map[string]string{"service": name},
+			Ports:    ports,
+		},
+	}, nil
+}
+
+// ReplicationController creates a ReplicationController for the specified service.
+func ReplicationController(name string, service *config.ServiceConfig, template api.PodTemplateSpec) *api.ReplicationController {
+	return &api.ReplicationController{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "ReplicationController",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: api.ReplicationControllerSpec{
+			Replicas: Replicas(service),
+			Selector: map[string]string{"service": name},
+			Template: &template,
+		},
+	}
+}
+
+// Deployment creates a Deployment for the specified service.
+func Deployment(name string, service *config.ServiceConfig, template api.PodTemplateSpec) *extensions.Deployment {
+	return &extensions.Deployment{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "extensions/v1beta1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: extensions.DeploymentSpec{
+			Replicas: Replicas(service),
+			Selector: &unversioned.LabelSelector{
+				MatchLabels: map[string]string{"service": name},
+			},
+			Template: template,
+		},
+	}
+}
+
+// DaemonSet creates a DaemonSet for the specified service.
+func DaemonSet(name string, service *config.ServiceConfig, template api.PodTemplateSpec) *extensions.DaemonSet {
+	return &extensions.DaemonSet{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "extensions/v1beta1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: extensions.DaemonSetSpec{
+			Selector: &unversioned.LabelSelector{
+				MatchLabels: map[string]string{"service": name},
+			},
+			Template: template,
+		},
+	}
+}
+
+// ReplicaSet creates a ReplicaSet for the specified service.
+func ReplicaSet(name string, service *config.ServiceConfig, template api.PodTemplateSpec) *extensions.ReplicaSet {
+	return &extensions.ReplicaSet{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "ReplicaSet",
+			APIVersion: "extensions/v1beta1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: Labels(name, service),
+		},
+		Spec: extensions.ReplicaSetSpec{
+			Replicas: Replicas(service),
+			Selector: &unversioned.LabelSelector{
+				MatchLabels: map[string]string{"service": name},
+			},
+			Template: template,
+		},
+	}
+}
+
+func envVars(name string, service *config.ServiceConfig) ([]api.EnvVar, error) {
+	var envs []api.EnvVar
+	for _, env := range service.Environment {
+		var key, value string
+		if i := strings.Index(env, "="); i >= 0 {
+			key, value = env[:i], env[i+1:]
+		} else if i := strings.Index(env, ":"); i >= 0 {
+			key, value = env[:i], strings.Trim(strings.TrimSpace(env[i+1:]), "'")
+		} else {
+			return nil, fmt.Errorf("Invalid container env %s for service %s", env, name)
+		}
+		envs = append(envs, api.EnvVar{
+			Name:  strings.TrimSpace(key),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	return envs, nil
+}
+
+// volumes converts the host volumes ("host:container[:mode]") of the service
+// into hostPath volumes and their mounts. Volumes are read-only unless the
+// "rw" mode is given.
+func volumes(name string, service *config.ServiceConfig) ([]api.VolumeMount, []api.Volume) {
+	var volumeMounts []api.VolumeMount
+	var volumes []api.Volume
+	for _, volume := range service.Volumes {
+		parts := strings.Split(volume, ":")
+		if len(parts) < 2 {
+			continue
+		}
+
+		readOnly := true
+		if len(parts) > 2 && parts[2] == "rw" {
+			readOnly = false
+		}
+
+		volumeName := fmt.Sprintf("%s-volume%d", name, len(volumes))
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      volumeName,
+			ReadOnly:  readOnly,
+			MountPath: strings.TrimSpace(parts[1]),
+		})
+		volumes = append(volumes, api.Volume{
+			Name: volumeName,
+			VolumeSource: api.VolumeSource{
+				HostPath: &api.HostPathVolumeSource{
+					Path: strings.TrimSpace(parts[0]),
+				},
+			},
+		})
+	}
+	return volumeMounts, volumes
+}
+
+// ParsePort splits a compose port ("[[ip:]host:]container[/protocol]") into
+// its published and container parts, and its protocol. The published part is
+// empty if the port is not published, the host IP is dropped.
+func ParsePort(name, port string) (string, int, api.Protocol, error) {
+	spec := strings.TrimSpace(port)
+	protocol := api.ProtocolTCP
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		switch strings.ToLower(spec[i+1:]) {
+		case "tcp":
+		case "udp":
+			protocol = api.ProtocolUDP
+		default:
+			return "", 0, "", fmt.Errorf("Invalid protocol of port %s for service %s", port, name)
+		}
+		spec = spec[:i]
+	}
+
+	published := ""
+	target := spec
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		target = spec[i+1:]
+		published = spec[:i]
+		if j := strings.LastIndex(published, ":"); j >= 0 {
+			published = published[j+1:]
+		}
+		published = strings.TrimSpace(published)
+	}
+
+	targetPort, err := strconv.Atoi(strings.TrimSpace(target))
+	if err != nil {
+		return "", 0, "", fmt.Errorf("Invalid container port %s for service %s", port, name)
+	}
+	return published, targetPort, protocol, nil
+}
+
+func containerPorts(name string, service *config.ServiceConfig) ([]api.ContainerPort, error) {
+	var ports []api.ContainerPort
+	for _, port := range service.Ports {
+		_, targetPort, protocol, err := ParsePort(name, port)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, api.ContainerPort{ContainerPort: targetPort, Protocol: protocol})
+	}
+	return ports, nil
+}
+
+func servicePorts(name string, service *config.ServiceConfig) ([]api.ServicePort, error) {
+	var ports []api.ServicePort
+	for _, port := range service.Ports {
+		published, targetPort, protocol, err := ParsePort(name, port)
+		if err != nil {
+			return nil, err
+		}
+
+		portNumber := targetPort
+		if published != "" {
+			if portNumber, err = strconv.Atoi(published); err != nil {
+				return nil, fmt.Errorf("Invalid container port %s for service %s", port, name)
+			}
+		}
+
+		// the ports of a service are named uniquely, the same port can be
+		// published for both protocols
+		portName := strconv.Itoa(portNumber)
+		if protocol == api.ProtocolUDP {
+			portName += "-udp"
+		}
+		ports = append(ports, api.ServicePort{
+			Name:       portName,
+			Port:       portNumber,
+			Protocol:   protocol,
+			TargetPort: intstr.FromInt(targetPort),
+		})
+	}
+	return ports, nil
+}
+
+func restartPolicy(name string, service *config.ServiceConfig) (api.RestartPolicy, error) {
+	switch service.Restart {
+	case "", "always":
+		return api.RestartPolicyAlways, nil
+	case "no":
+		return api.RestartPolicyNever, nil
+	case "on-failure":
+		return api.RestartPolicyOnFailure, nil
+	default:
+		return "", fmt.Errorf("Unknown restart policy %s for service %s", service.Restart, name)
+	}
+}
@@ -0,0 +1,161 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+)
+
+func newTestProject(t *testing.T, composeFile string) *project.Project {
+	p := project.NewProject(&project.Context{}, nil, nil)
+	if err := p.Load([]byte(composeFile)); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPodTemplate(t *testing.T) {
+	template, err := PodTemplate("web", &config.ServiceConfig{
+		Image:       "nginx",
+		Command:     []string{"nginx", "-g", "daemon off;"},
+		Environment: []string{"FOO=bar", "EMPTY="},
+		Ports:       []string{"8080:80", "443"},
+		Volumes:     []string{"/data", "/srv:/srv", "/etc/nginx:/etc/nginx:rw"},
+		Labels:      map[string]string{"tier": "front"},
+		Restart:     "on-failure",
+		Privileged:  true,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]string{"service": "web", "tier": "front"}, template.ObjectMeta.Labels)
+	assert.Equal(t, api.RestartPolicyOnFailure, template.Spec.RestartPolicy)
+
+	container := template.Spec.Containers[0]
+	assert.Equal(t, "web", container.Name)
+	assert.Equal(t, "nginx", container.Image)
+	assert.Equal(t, []string{"nginx", "-g", "daemon off;"}, container.Command)
+	assert.Equal(t, []api.EnvVar{{Name: "FOO", Value: "bar"}, {Name: "EMPTY", Value: ""}}, container.Env)
+	assert.Equal(t, []api.ContainerPort{{ContainerPort: 80, Protocol: api.ProtocolTCP}, {ContainerPort: 443, Protocol: api.ProtocolTCP}}, container.Ports)
+	assert.True(t, *container.SecurityContext.Privileged)
+
+	assert.Equal(t, []api.VolumeMount{
+		{Name: "web-volume0", ReadOnly: true, MountPath: "/srv"},
+		{Name: "web-volume1", ReadOnly: false, MountPath: "/etc/nginx"},
+	}, container.VolumeMounts)
+	assert.Equal(t, 2, len(template.Spec.Volumes))
+	assert.Equal(t, "/etc/nginx", template.Spec.Volumes[1].HostPath.Path)
+}
+
+func TestPodTemplateInvalid(t *testing.T) {
+	_, err := PodTemplate("web", &config.ServiceConfig{Ports: []string{"http"}})
+	assert.NotNil(t, err)
+
+	_, err = PodTemplate("web", &config.ServiceConfig{Restart: "sometimes"})
+	assert.NotNil(t, err)
+}
+
+func TestParsePort(t *testing.T) {
+	for _, c := range []struct {
+		port      string
+		published string
+		target    int
+		protocol  api.Protocol
+	}{
+		{"80", "", 80, api.ProtocolTCP},
+		{"8080:80", "8080", 80, api.ProtocolTCP},
+		{"127.0.0.1:8080:80", "8080", 80, api.ProtocolTCP},
+		{"127.0.0.1::80", "", 80, api.ProtocolTCP},
+		{"80/udp", "", 80, api.ProtocolUDP},
+		{"8080:80/tcp", "8080", 80, api.ProtocolTCP},
+		{"127.0.0.1:5353:53/udp", "5353", 53, api.ProtocolUDP},
+	} {
+		published, target, protocol, err := ParsePort("web", c.port)
+		assert.Nil(t, err, c.port)
+		assert.Equal(t, c.published, published, c.port)
+		assert.Equal(t, c.target, target, c.port)
+		assert.Equal(t, c.protocol, protocol, c.port)
+	}
+
+	for _, port := range []string{"http", "8080:http", "80/sctp"} {
+		_, _, _, err := ParsePort("web", port)
+		assert.NotNil(t, err, port)
+	}
+}
+
+func TestService(t *testing.T) {
+	svc, err := Service("web", &config.ServiceConfig{
+		Ports: []string{"8080:80", "443", "53:53/udp"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Service", Kind(svc))
+	assert.Equal(t, "web", Name(svc))
+	assert.Equal(t, map[string]string{"service": "web"}, svc.Spec.Selector)
+	assert.Equal(t, []api.ServicePort{
+		{Name: "8080", Port: 8080, Protocol: api.ProtocolTCP, TargetPort: intstr.FromInt(80)},
+		{Name: "443", Port: 443, Protocol: api.ProtocolTCP, TargetPort: intstr.FromInt(443)},
+		{Name: "53-udp", Port: 53, Protocol: api.ProtocolUDP, TargetPort: intstr.FromInt(53)},
+	}, svc.Spec.Ports)
+}
+
+func TestTransform(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+  worker:
+    image: busybox
+`)
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateRC: true})
+	assert.Nil(t, err)
+
+	kinds := []string{}
+	for _, obj := range objects {
+		kinds = append(kinds, Kind(obj)+"/"+Name(obj))
+	}
+	assert.Equal(t, []string{"Service/web", "ReplicationController/web", "ReplicationController/worker"}, kinds)
+
+	objects, err = (&Converter{}).Transform(p, ConvertOptions{CreateDeployment: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(objects))
+
+	deployment, ok := objects[1].(*extensions.Deployment)
+	assert.True(t, ok)
+	assert.Equal(t, "nginx", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, map[string]string{"service": "web"}, deployment.Spec.Selector.MatchLabels)
+}
+
+func TestTransformProfiles(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    depends_on:
+      - cache
+  cache:
+    image: redis
+    profiles: [cache]
+  admin:
+    image: adminer
+    profiles: [debug]
+`)
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateRC: true})
+	assert.Nil(t, err)
+
+	kinds := []string{}
+	for _, obj := range objects {
+		kinds = append(kinds, Kind(obj)+"/"+Name(obj))
+	}
+	assert.Equal(t, []string{"ReplicationController/cache", "ReplicationController/web"}, kinds)
+}
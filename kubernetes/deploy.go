@@ -0,0 +1,249 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/go-units"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+
+	"github.com/docker/libcompose/config"
+)
+
+// The node labels set by the kubelet matching the node attributes of the
+// placement constraints, node labels ("node.labels.<label>") match the node
+// labels of the same name.
+var placementLabels = map[string]string{
+	"node.hostname":      "kubernetes.io/hostname",
+	"node.platform.os":   "beta.kubernetes.io/os",
+	"node.platform.arch": "beta.kubernetes.io/arch",
+}
+
+// Replicas returns the number of replicas of the specified service, set by
+// deploy.replicas, 1 by default.
+func Replicas(service *config.ServiceConfig) int {
+	if service.Deploy.Replicas != nil {
+		return *service.Deploy.Replicas
+	}
+	return 1
+}
+
+// Global returns whether the specified service runs on every node
+// (deploy.mode: global), that is as a DaemonSet.
+func Global(service *config.ServiceConfig) bool {
+	return service.Deploy.Mode == "global"
+}
+
+// resources converts the resources of the specified service into the
+// requests (its reservations) and limits of its container.
+func resources(name string, service *config.ServiceConfig) (api.ResourceRequirements, error) {
+	requirements := api.ResourceRequirements{}
+
+	var err error
+	if requirements.Limits, err = resourceList(name, service.Deploy.Resources.Limits); err != nil {
+		return requirements, err
+	}
+	if requirements.Requests, err = resourceList(name, service.Deploy.Resources.Reservations); err != nil {
+		return requirements, err
+	}
+	return requirements, nil
+}
+
+func resourceList(name string, r *config.Resource) (api.ResourceList, error) {
+	if r == nil || (r.CPUs == "" && r.Memory == "") {
+		return nil, nil
+	}
+
+	list := api.ResourceList{}
+	if r.CPUs != "" {
+		cpus, err := resource.ParseQuantity(r.CPUs)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cpus %s for service %s", r.CPUs, name)
+		}
+		list[api.ResourceCPU] = *cpus
+	}
+	if r.Memory != "" {
+		// the units of the compose files are binary ones: 1M is 1Mi
+		bytes, err := units.RAMInBytes(r.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid memory %s for service %s", r.Memory, name)
+		}
+		list[api.ResourceMemory] = *resource.NewQuantity(bytes, resource.BinarySI)
+	}
+	return list, nil
+}
+
+// placement converts the placement constraints of the specified service into
+// a node selector, for the "==" constraints, and a node affinity, for the
+// "!=" ones. Constraints on the role, the id or the engine labels of the
+// nodes have no Kubernetes equivalent, they are ignored with a warning.
+func placement(name string, service *config.ServiceConfig) (map[string]string, *api.Affinity, error) {
+	var nodeSelector map[string]string
+	var requirements []api.NodeSelectorRequirement
+	for _, constraint := range service.Deploy.Placement.Constraints {
+		operator := "=="
+		parts := strings.SplitN(constraint, operator, 2)
+		if len(parts) != 2 {
+			operator = "!="
+			parts = strings.SplitN(constraint, operator, 2)
+		}
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, nil, fmt.Errorf("Invalid placement constraint %s for service %s", constraint, name)
+		}
+		attribute, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		label, ok := placementLabels[attribute]
+		if !ok && strings.HasPrefix(attribute, "node.labels.") {
+			label, ok = strings.TrimPrefix(attribute, "node.labels."), true
+		}
+		if !ok {
+			logrus.Warnf("Service %s has the placement constraint %s, which is not supported", name, constraint)
+			continue
+		}
+
+		if operator == "!=" {
+			requirements = append(requirements, api.NodeSelectorRequirement{
+				Key:      label,
+				Operator: api.NodeSelectorOpNotIn,
+				Values:   []string{value},
+			})
+			continue
+		}
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		if previous, ok := nodeSelector[label]; ok && previous != value {
+			return nil, nil, fmt.Errorf("Conflicting placement constraints on %s for service %s", attribute, name)
+		}
+		nodeSelector[label] = value
+	}
+
+	if len(requirements) == 0 {
+		return nodeSelector, nil, nil
+	}
+	return nodeSelector, &api.Affinity{
+		NodeAffinity: &api.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &api.NodeSelector{
+				NodeSelectorTerms: []api.NodeSelectorTerm{{MatchExpressions: requirements}},
+			},
+		},
+	}, nil
+}
+
+// affinityAnnotation returns the value of the annotation declaring the
+// specified affinity, the vendored API having no affinity field.
+func affinityAnnotation(affinity *api.Affinity) (string, error) {
+	data, err := json.Marshal(affinity)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// updateRollout returns the update strategy set by the update_config of the
+// specified service, falling back to the specified defaults. The services
+// are updated parallelism (1 by default, 0 for all) pods at a time, stopping
+// the old pods first unless the order is start-first; the delay between two
+// updates becomes the time a new pod must be ready before the next update.
+func updateRollout(name string, service *config.ServiceConfig, defaults RolloutOptions) (RolloutOptions, error) {
+	updateConfig := service.Deploy.UpdateConfig
+	if updateConfig == nil {
+		return defaults, nil
+	}
+
+	pods := "1"
+	if updateConfig.Parallelism != nil {
+		pods = strconv.Itoa(*updateConfig.Parallelism)
+		if *updateConfig.Parallelism == 0 {
+			pods = "100%"
+		}
+	}
+	if updateConfig.Order == "start-first" {
+		defaults.MaxSurge, defaults.MaxUnavailable = pods, "0"
+	} else {
+		defaults.MaxSurge, defaults.MaxUnavailable = "0", pods
+	}
+
+	if updateConfig.Delay != "" {
+		delay, err := time.ParseDuration(updateConfig.Delay)
+		if err != nil || delay < 0 {
+			return defaults, fmt.Errorf("Invalid update delay %s for service %s", updateConfig.Delay, name)
+		}
+		defaults.MinReadySeconds = strconv.Itoa(int((delay + time.Second - 1) / time.Second))
+	}
+
+	if updateConfig.FailureAction != "" || updateConfig.Monitor != "" || updateConfig.MaxFailureRatio != 0 {
+		logrus.Warnf("Service %s sets the failure action, the monitoring period or the max failure ratio of its updates, which are not supported", name)
+	}
+	return defaults, nil
+}
+
+// mergeNodeSelectors adds to a node selector the labels of another one it
+// does not select yet, and returns the sorted labels selecting different values.
+func mergeNodeSelectors(nodeSelector map[string]string, other map[string]string) (map[string]string, []string) {
+	var conflicts []string
+	for label, value := range other {
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		if previous, ok := nodeSelector[label]; ok {
+			if previous != value {
+				conflicts = append(conflicts, label)
+			}
+			continue
+		}
+		nodeSelector[label] = value
+	}
+	sort.Strings(conflicts)
+	return nodeSelector, conflicts
+}
+
+// mergeAffinities merges the affinity annotations of two services sharing a
+// pod, whose nodes must match the node affinities of both. The node selector
+// terms of an affinity are ORed and their expressions ANDed: a node matches
+// the merged terms, each one combining a term of both affinities, when it
+// matches a term of each.
+func mergeAffinities(affinity, other string) (string, error) {
+	if affinity == "" {
+		return other, nil
+	}
+	if other == "" {
+		return affinity, nil
+	}
+
+	var left, right api.Affinity
+	if err := json.Unmarshal([]byte(affinity), &left); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal([]byte(other), &right); err != nil {
+		return "", err
+	}
+
+	terms := []api.NodeSelectorTerm{}
+	for _, leftTerm := range nodeSelectorTerms(&left) {
+		for _, rightTerm := range nodeSelectorTerms(&right) {
+			expressions := append(append([]api.NodeSelectorRequirement{}, leftTerm.MatchExpressions...), rightTerm.MatchExpressions...)
+			terms = append(terms, api.NodeSelectorTerm{MatchExpressions: expressions})
+		}
+	}
+	return affinityAnnotation(&api.Affinity{
+		NodeAffinity: &api.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &api.NodeSelector{NodeSelectorTerms: terms},
+		},
+	})
+}
+
+// nodeSelectorTerms returns the terms of the node affinity required by an
+// affinity, a term matching every node if it requires none.
+func nodeSelectorTerms(affinity *api.Affinity) []api.NodeSelectorTerm {
+	if affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return []api.NodeSelectorTerm{{}}
+	}
+	return affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+}
@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestTransformDeploy(t *testing.T) {
+	p := newTestProject(t, `
+version: '3'
+services:
+  web:
+    image: nginx
+    deploy:
+      replicas: 3
+      resources:
+        limits:
+          cpus: '0.5'
+          memory: 512M
+        reservations:
+          memory: 128M
+      placement:
+        constraints:
+          - node.labels.disk == ssd
+          - node.platform.os == linux
+          - node.hostname != build
+          - node.role == worker
+      update_config:
+        parallelism: 2
+        delay: 1500ms
+        order: start-first
+  agent:
+    image: datadog/agent
+    deploy:
+      mode: global
+`)
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateDeployment: true, CreateRC: true})
+	assert.Nil(t, err)
+
+	kinds := []string{}
+	for _, obj := range objects {
+		kinds = append(kinds, Kind(obj)+"/"+Name(obj))
+	}
+	assert.Equal(t, []string{"DaemonSet/agent", "ReplicationController/web", "Deployment/web"}, kinds)
+
+	rc := objects[1].(*api.ReplicationController)
+	assert.Equal(t, 3, rc.Spec.Replicas)
+
+	deployment := objects[2].(*extensions.Deployment)
+	assert.Equal(t, 3, deployment.Spec.Replicas)
+	assert.Equal(t, intstr.FromInt(2), deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, intstr.FromInt(0), deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, 2, deployment.Spec.MinReadySeconds)
+
+	spec := deployment.Spec.Template.Spec
+	assert.Equal(t, api.ResourceRequirements{
+		Limits: api.ResourceList{
+			api.ResourceCPU:    resource.MustParse("500m"),
+			api.ResourceMemory: resource.MustParse("512Mi"),
+		},
+		Requests: api.ResourceList{
+			api.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}, spec.Containers[0].Resources)
+	assert.Equal(t, map[string]string{"disk": "ssd", "beta.kubernetes.io/os": "linux"}, spec.NodeSelector)
+
+	affinity, err := api.GetAffinityFromPodAnnotations(deployment.Spec.Template.Annotations)
+	assert.Nil(t, err)
+	assert.Equal(t, []api.NodeSelectorRequirement{{
+		Key:      "kubernetes.io/hostname",
+		Operator: api.NodeSelectorOpNotIn,
+		Values:   []string{"build"},
+	}}, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions)
+}
+
+func TestSetRolloutUpdateConfig(t *testing.T) {
+	parallelism := 0
+	service := &config.ServiceConfig{
+		Image:  "nginx",
+		Labels: map[string]string{MaxUnavailableLabel: "1"},
+		Deploy: config.DeployConfig{
+			UpdateConfig: &config.UpdateConfig{Parallelism: &parallelism},
+		},
+	}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+	deployment := Deployment("web", service, template)
+
+	// the labels take precedence over the update config, which takes
+	// precedence over the defaults
+	assert.Nil(t, SetRollout(deployment, "web", service, RolloutOptions{MaxSurge: "25%", MinReadySeconds: "5"}))
+	assert.Equal(t, intstr.FromInt(0), deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, intstr.FromInt(1), deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, 5, deployment.Spec.MinReadySeconds)
+	assert.Equal(t, 1, deployment.Spec.Replicas)
+
+	service.Labels = nil
+	assert.Nil(t, SetRollout(deployment, "web", service, RolloutOptions{}))
+	assert.Equal(t, intstr.FromString("100%"), deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+}
+
+func TestDeployInvalid(t *testing.T) {
+	for _, deploy := range []config.DeployConfig{
+		{Resources: config.Resources{Limits: &config.Resource{CPUs: "half"}}},
+		{Resources: config.Resources{Reservations: &config.Resource{Memory: "lots"}}},
+		{Placement: config.Placement{Constraints: []string{"node.labels.disk"}}},
+		{Placement: config.Placement{Constraints: []string{"node.labels.disk == ssd", "node.labels.disk == hdd"}}},
+	} {
+		_, err := PodTemplate("web", &config.ServiceConfig{Image: "nginx", Deploy: deploy})
+		assert.NotNil(t, err)
+	}
+
+	service := &config.ServiceConfig{
+		Image: "nginx",
+		Deploy: config.DeployConfig{
+			UpdateConfig: &config.UpdateConfig{Delay: "soon"},
+		},
+	}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+	assert.NotNil(t, SetRollout(Deployment("web", service, template), "web", service, RolloutOptions{}))
+}
@@ -0,0 +1,156 @@
+package kubernetes
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// serverMetadata holds the metadata fields populated by the API server.
+var serverMetadata = []string{
+	"namespace",
+	"selfLink",
+	"uid",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+}
+
+// Diff returns the unified diff between the live and the desired state of an
+// object, empty if they do not differ. Server-populated fields (status,
+// server metadata, defaulted values…) are stripped from the live object: only
+// the fields set in the desired object are compared.
+func Diff(desired, live runtime.Object) (string, error) {
+	desiredValue, err := toValue(desired)
+	if err != nil {
+		return "", err
+	}
+	liveValue, err := toValue(live)
+	if err != nil {
+		return "", err
+	}
+
+	stripServerFields(desiredValue)
+	stripServerFields(liveValue)
+	desiredValue = dropEmpty(desiredValue)
+	liveValue = prune(desiredValue, liveValue)
+
+	desiredYaml, err := yaml.Marshal(desiredValue)
+	if err != nil {
+		return "", err
+	}
+	liveYaml, err := yaml.Marshal(liveValue)
+	if err != nil {
+		return "", err
+	}
+
+	name := Kind(desired) + "/" + Name(desired)
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYaml)),
+		B:        difflib.SplitLines(string(desiredYaml)),
+		FromFile: "live/" + name,
+		ToFile:   "converted/" + name,
+		Context:  3,
+	})
+}
+
+// stripServerFields removes the server-populated fields of an object, and its
+// type information since objects fetched with the client have none.
+func stripServerFields(value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(object, "kind")
+	delete(object, "apiVersion")
+	delete(object, "status")
+	if meta, ok := object["metadata"].(map[string]interface{}); ok {
+		for _, field := range serverMetadata {
+			delete(meta, field)
+		}
+	}
+}
+
+// toValue converts an object into its generic json representation.
+func toValue(obj runtime.Object) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// dropEmpty removes the empty strings, lists and maps from the maps of a value:
+// the internal API types do not omit them, while they are unset values
+// defaulted by the API server.
+func dropEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			child = dropEmpty(child)
+			if isEmpty(child) {
+				delete(v, key)
+			} else {
+				v[key] = child
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = dropEmpty(v[i])
+		}
+	}
+	return value
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// prune removes from live the map keys that are not set in desired, so that
+// the values defaulted by the API server do not show up as differences. Lists
+// of the same length are pruned element by element.
+func prune(desired, live interface{}) interface{} {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := map[string]interface{}{}
+		for key, value := range liveMap {
+			if desiredChild, ok := desiredValue[key]; ok {
+				pruned[key] = prune(desiredChild, value)
+			}
+		}
+		return pruned
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok || len(liveList) != len(desiredValue) {
+			return live
+		}
+		pruned := make([]interface{}, len(liveList))
+		for i := range liveList {
+			pruned[i] = prune(desiredValue[i], liveList[i])
+		}
+		return pruned
+	}
+	return live
+}
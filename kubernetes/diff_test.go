@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestDiff(t *testing.T) {
+	service := &config.ServiceConfig{Image: "nginx", Ports: []string{"80"}}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+	desired := ReplicationController("web", service, template)
+
+	// the live object has no type information, server metadata, status and defaulted values
+	live := ReplicationController("web", service, template)
+	live.TypeMeta.Kind = ""
+	live.TypeMeta.APIVersion = ""
+	live.ObjectMeta.Namespace = api.NamespaceDefault
+	live.ObjectMeta.ResourceVersion = "42"
+	live.ObjectMeta.UID = "0b5e3e4e-4ae4-11e6-9b2b-0800277ad4a8"
+	live.Status.Replicas = 1
+	liveTemplate := *live.Spec.Template
+	liveTemplate.Spec.DNSPolicy = api.DNSClusterFirst
+	liveTemplate.Spec.Containers = []api.Container{liveTemplate.Spec.Containers[0]}
+	liveTemplate.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+	liveTemplate.Spec.Containers[0].ImagePullPolicy = api.PullIfNotPresent
+	live.Spec.Template = &liveTemplate
+
+	diff, err := Diff(desired, live)
+	assert.Nil(t, err)
+	assert.Equal(t, "", diff)
+
+	liveTemplate.Spec.Containers[0].Image = "nginx:1.10"
+	live.Spec.Replicas = 3
+
+	diff, err = Diff(desired, live)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(diff, "--- live/ReplicationController/web\n+++ converted/ReplicationController/web\n"), diff)
+	assert.Contains(t, diff, "-      - image: nginx:1.10\n")
+	assert.Contains(t, diff, "+      - image: nginx\n")
+	assert.Contains(t, diff, "-  replicas: 3\n")
+	assert.Contains(t, diff, "+  replicas: 1\n")
+}
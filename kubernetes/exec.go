@@ -0,0 +1,264 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/labels"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/remotecommand"
+
+	"github.com/docker/libcompose/config"
+)
+
+// OneOffLabel is set, instead of the service label, on the pods started by
+// Run: they are not selected by the service Services and controllers.
+const OneOffLabel = "kompose.io/one-off"
+
+// ExitError is returned when a command executed in a container fails.
+type ExitError struct {
+	Message  string
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
+// exitCodeRegexp matches the exit code at the end of the message of a failed
+// command, like "command terminated with exit code 3" or "Error executing in
+// Docker Container: 3".
+var exitCodeRegexp = regexp.MustCompile(`(?:exit code|Container):? (\d+)$`)
+
+// podTimeout is how long Run waits for the pod to start, and to terminate.
+const podTimeout = 5 * time.Minute
+
+// ReadyPod returns a running and ready pod of the specified service.
+func ReadyPod(c *client.Client, namespace, name string) (*api.Pod, error) {
+	pods, err := c.Pods(namespace).List(api.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"service": name}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pod := readyPod(pods.Items)
+	if pod == nil {
+		return nil, fmt.Errorf("No ready pod found for service %s", name)
+	}
+	return pod, nil
+}
+
+// readyPod returns the first running and ready pod, by name, nil if there are none.
+func readyPod(pods []api.Pod) *api.Pod {
+	sorted := append([]api.Pod{}, pods...)
+	sort.Sort(podsByName(sorted))
+	for i := range sorted {
+		if isReady(&sorted[i]) {
+			return &sorted[i]
+		}
+	}
+	return nil
+}
+
+// isReady returns whether a pod is running, ready and not being deleted.
+func isReady(pod *api.Pod) bool {
+	if pod.Status.Phase != api.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == api.PodReady {
+			return condition.Status == api.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Exec executes a command in a container of a pod, attaching its standard
+// streams. When tty is set, stderr is merged into stdout by the terminal.
+func Exec(c *client.Client, config *restclient.Config, namespace string, pod *api.Pod, command []string, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	req := c.Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&api.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, api.ParameterCodec)
+
+	return stream(config, req.URL(), stdin, stdout, stderr, tty)
+}
+
+// stream attaches the standard streams to the streaming subresource (exec,
+// attach) at the specified URL until the command terminates.
+func stream(config *restclient.Config, u *url.URL, stdin io.Reader, stdout, stderr io.Writer, tty bool) error {
+	executor, err := remotecommand.NewExecutor(config, "POST", u)
+	if err != nil {
+		return err
+	}
+	if tty {
+		stderr = nil
+	}
+	return exitError(executor.Stream(stdin, stdout, stderr, tty))
+}
+
+// exitError returns an ExitError when the error sent by the API server
+// reports the exit code of the command.
+func exitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	match := exitCodeRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	exitCode, _ := strconv.Atoi(match[1])
+	return &ExitError{Message: err.Error(), ExitCode: exitCode}
+}
+
+// OneOffPod creates a pod running the specified command, or the service
+// command if it is empty, once.
+func OneOffPod(name string, service *config.ServiceConfig, command []string) (*api.Pod, error) {
+	template, err := PodTemplate(name, service)
+	if err != nil {
+		return nil, err
+	}
+
+	podLabels := map[string]string{OneOffLabel: name}
+	for key, value := range template.Labels {
+		if key != "service" {
+			podLabels[key] = value
+		}
+	}
+
+	spec := template.Spec
+	spec.RestartPolicy = api.RestartPolicyNever
+	if len(command) > 0 {
+		spec.Containers[0].Command = command
+	}
+
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			GenerateName: name + "-run-",
+			Labels:       podLabels,
+			Annotations:  template.Annotations,
+		},
+		Spec: spec,
+	}, nil
+}
+
+// Run creates the specified pod and attaches the standard streams to its
+// container, like docker-compose run does, the container being allocated a
+// terminal when tty is set. Once it terminates, it removes the pod and returns
+// the exit code of its container.
+func Run(c *client.Client, config *restclient.Config, namespace string, pod *api.Pod, stdin io.Reader, stdout, stderr io.Writer, tty bool) (int, error) {
+	container := &pod.Spec.Containers[0]
+	container.Stdin = stdin != nil
+	container.StdinOnce = stdin != nil
+	container.TTY = tty
+
+	created, err := c.Pods(namespace).Create(pod)
+	if err != nil {
+		return 0, err
+	}
+	name := created.Name
+	defer func() {
+		if err := c.Pods(namespace).Delete(name, nil); err != nil {
+			logrus.Warnf("Failed to remove pod %s: %v", name, err)
+		}
+	}()
+
+	started, err := waitForPod(c, namespace, name, podTimeout, func(pod *api.Pod) bool {
+		return pod.Status.Phase != api.PodPending
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if started.Status.Phase == api.PodRunning {
+		req := c.Post().
+			Namespace(namespace).
+			Resource("pods").
+			Name(name).
+			SubResource("attach").
+			VersionedParams(&api.PodAttachOptions{
+				Container: container.Name,
+				Stdin:     stdin != nil,
+				Stdout:    true,
+				Stderr:    !tty,
+				TTY:       tty,
+			}, api.ParameterCodec)
+		if err := stream(config, req.URL(), stdin, stdout, stderr, tty); err != nil {
+			if _, ok := err.(*ExitError); !ok {
+				return 0, err
+			}
+		}
+	} else {
+		// the command terminated before it could be attached to
+		logs, err := c.Pods(namespace).GetLogs(name, &api.PodLogOptions{}).Stream()
+		if err != nil {
+			return 0, err
+		}
+		_, err = io.Copy(stdout, logs)
+		logs.Close()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	terminated, err := waitForPod(c, namespace, name, podTimeout, func(pod *api.Pod) bool {
+		return pod.Status.Phase == api.PodSucceeded || pod.Status.Phase == api.PodFailed
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, status := range terminated.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return status.State.Terminated.ExitCode, nil
+		}
+	}
+	return 0, fmt.Errorf("No exit code found for pod %s", name)
+}
+
+// waitForPod polls a pod until the condition is met, or the timeout expires.
+// It fails when the container cannot start because its image cannot be pulled.
+func waitForPod(c *client.Client, namespace, name string, timeout time.Duration, condition func(*api.Pod) bool) (*api.Pod, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pod, err := c.Pods(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		if condition(pod) {
+			return pod, nil
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil {
+				switch waiting.Reason {
+				case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+					return nil, fmt.Errorf("Pod %s cannot start: %s %s", name, waiting.Reason, waiting.Message)
+				}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for pod %s, its phase is %s", name, pod.Status.Phase)
+		}
+		time.Sleep(time.Second)
+	}
+}
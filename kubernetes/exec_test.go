@@ -0,0 +1,52 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestReadyPod(t *testing.T) {
+	ready := []api.PodCondition{{Type: api.PodReady, Status: api.ConditionTrue}}
+	pods := []api.Pod{
+		{ObjectMeta: api.ObjectMeta{Name: "web-c"}, Status: api.PodStatus{Phase: api.PodRunning, Conditions: ready}},
+		{ObjectMeta: api.ObjectMeta{Name: "web-a"}, Status: api.PodStatus{Phase: api.PodPending}},
+		{ObjectMeta: api.ObjectMeta{Name: "web-b"}, Status: api.PodStatus{Phase: api.PodRunning, Conditions: ready}},
+	}
+
+	assert.Equal(t, "web-b", readyPod(pods).Name)
+	assert.Nil(t, readyPod(pods[1:2]))
+}
+
+func TestOneOffPod(t *testing.T) {
+	service := &config.ServiceConfig{
+		Image:   "busybox",
+		Command: []string{"sleep", "3600"},
+		Labels:  map[string]string{"tier": "back"},
+	}
+
+	pod, err := OneOffPod("worker", service, []string{"ls", "-la"})
+	assert.Nil(t, err)
+	assert.Equal(t, "worker-run-", pod.GenerateName)
+	assert.Equal(t, map[string]string{OneOffLabel: "worker", "tier": "back"}, pod.Labels)
+	assert.Equal(t, api.RestartPolicyNever, pod.Spec.RestartPolicy)
+	assert.Equal(t, []string{"ls", "-la"}, pod.Spec.Containers[0].Command)
+
+	pod, err = OneOffPod("worker", service, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sleep", "3600"}, pod.Spec.Containers[0].Command)
+}
+
+func TestExitError(t *testing.T) {
+	assert.Nil(t, exitError(nil))
+	assert.Equal(t, &ExitError{Message: "error executing remote command: command terminated with exit code 3", ExitCode: 3},
+		exitError(errors.New("error executing remote command: command terminated with exit code 3")))
+	assert.Equal(t, 126, exitError(errors.New("error executing remote command: Error executing in Docker Container: 126")).(*ExitError).ExitCode)
+
+	err := errors.New("error sending request: dial tcp 127.0.0.1:8080: connection refused")
+	assert.Equal(t, err, exitError(err))
+}
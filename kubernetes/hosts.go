@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/docker/libcompose/config"
+)
+
+// The vendored API has neither hostAliases nor a pod DNS config. The extra
+// hosts of a service are appended to /etc/hosts by an init container instead:
+// the file is shared by the containers of a pod and only written once, before
+// the init containers run. Init containers are declared with an annotation,
+// alpha up to Kubernetes 1.3 and beta from 1.4.
+const (
+	InitContainersAlphaAnnotation = "pod.alpha.kubernetes.io/init-containers"
+	InitContainersBetaAnnotation  = "pod.beta.kubernetes.io/init-containers"
+
+	// HostsImage is the image of the init container writing the extra hosts.
+	HostsImage = "busybox"
+)
+
+// podAnnotations returns the annotations of the pod template of the specified
+// service: the init container writing its extra hosts. The pods always use the
+// cluster DNS, the dns and dns_search of a service are ignored with a warning.
+func podAnnotations(name string, service *config.ServiceConfig) (map[string]string, error) {
+	if len(service.DNS) > 0 || len(service.DNSSearch) > 0 {
+		logrus.Warnf("Service %s sets dns or dns_search, which Kubernetes cannot apply: the pods always use the cluster DNS, ignoring them", name)
+	}
+
+	if len(service.ExtraHosts) == 0 {
+		return nil, nil
+	}
+	initContainers, err := hostsInitContainers(name, service.ExtraHosts)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		InitContainersAlphaAnnotation: initContainers,
+		InitContainersBetaAnnotation:  initContainers,
+	}, nil
+}
+
+// hostsInitContainers returns the JSON definition of the init container
+// appending the extra hosts ("host:ip") to /etc/hosts, unless they are already
+// there: the init containers run again when the pod restarts.
+func hostsInitContainers(name string, extraHosts []string) (string, error) {
+	// the entries are passed as arguments of the script, they need no quoting
+	command := []string{"sh", "-c", `for entry; do grep -qxF "$entry" /etc/hosts || printf '%s\n' "$entry" >> /etc/hosts; done`, "hosts"}
+	for _, extraHost := range extraHosts {
+		parts := strings.SplitN(extraHost, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return "", fmt.Errorf("Invalid extra host %s for service %s", extraHost, name)
+		}
+		command = append(command, fmt.Sprintf("%s\t%s", strings.TrimSpace(parts[1]), strings.TrimSpace(parts[0])))
+	}
+
+	data, err := json.Marshal([]v1.Container{{
+		Name:    name + "-hosts",
+		Image:   HostsImage,
+		Command: command,
+	}})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,49 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api/v1"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestPodTemplateExtraHosts(t *testing.T) {
+	template, err := PodTemplate("web", &config.ServiceConfig{
+		Image:      "nginx",
+		ExtraHosts: []string{"somehost:162.242.195.82", "otherhost: 50.31.209.229"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, template.Annotations[InitContainersAlphaAnnotation], template.Annotations[InitContainersBetaAnnotation])
+
+	initContainers := []v1.Container{}
+	assert.Nil(t, json.Unmarshal([]byte(template.Annotations[InitContainersBetaAnnotation]), &initContainers))
+	assert.Equal(t, []v1.Container{{
+		Name:  "web-hosts",
+		Image: HostsImage,
+		Command: []string{
+			"sh", "-c", `for entry; do grep -qxF "$entry" /etc/hosts || printf '%s\n' "$entry" >> /etc/hosts; done`, "hosts",
+			"162.242.195.82\tsomehost",
+			"50.31.209.229\totherhost",
+		},
+	}}, initContainers)
+
+	_, err = PodTemplate("web", &config.ServiceConfig{Image: "nginx", ExtraHosts: []string{"somehost"}})
+	assert.NotNil(t, err)
+}
+
+func TestPodTemplateDNS(t *testing.T) {
+	// the pods always use the cluster DNS
+	for _, service := range []*config.ServiceConfig{
+		{Image: "nginx", DNS: []string{"8.8.8.8", "9.9.9.9"}},
+		{Image: "nginx", DNSSearch: []string{"example.com"}},
+		{Image: "nginx"},
+	} {
+		template, err := PodTemplate("web", service)
+		assert.Nil(t, err)
+		assert.Nil(t, template.Annotations)
+	}
+}
@@ -0,0 +1,407 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+
+	"github.com/docker/libcompose/config"
+)
+
+// workload holds the pod template of a Deployment, ReplicationController or
+// ReplicaSet being imported.
+type workload struct {
+	kind     string
+	name     string
+	replicas int
+	template *api.PodTemplateSpec
+}
+
+// importer accumulates the objects found in the imported manifests.
+type importer struct {
+	workloads  []workload
+	services   []api.Service
+	configMaps map[string]map[string]string
+	warnings   []string
+}
+
+// Import converts Kubernetes manifests (json or yaml, possibly with several
+// documents or a List) back into a compose v2 configuration. Each container of
+// a Deployment, ReplicationController or ReplicaSet becomes a compose service,
+// the Services selecting it give its published ports and ConfigMaps are used
+// to resolve its environment. Everything that cannot be represented in the
+// compose file is returned as a warning.
+func Import(manifests ...[]byte) (*config.Config, []string, error) {
+	i := &importer{
+		configMaps: map[string]map[string]string{},
+	}
+
+	for _, manifest := range manifests {
+		for _, document := range splitDocuments(manifest) {
+			if err := i.add(document); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	cfg, err := i.config()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, i.warnings, nil
+}
+
+func (i *importer) warnf(format string, args ...interface{}) {
+	i.warnings = append(i.warnings, fmt.Sprintf(format, args...))
+}
+
+// documentSeparator matches the lines separating the documents of a YAML
+// stream, which only hold "---" and maybe a comment.
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*(?:#.*)?\r?$`)
+
+func splitDocuments(manifest []byte) [][]byte {
+	documents := [][]byte{}
+	for _, document := range documentSeparator.Split(string(manifest), -1) {
+		if len(strings.TrimSpace(document)) > 0 {
+			documents = append(documents, []byte(document))
+		}
+	}
+	return documents
+}
+
+func (i *importer) add(document []byte) error {
+	var typeMeta unversioned.TypeMeta
+	if err := yaml.Unmarshal(document, &typeMeta); err != nil {
+		return err
+	}
+
+	switch typeMeta.Kind {
+	case "List":
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := yaml.Unmarshal(document, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if err := i.add(item); err != nil {
+				return err
+			}
+		}
+	case "Deployment":
+		var deployment extensions.Deployment
+		if err := yaml.Unmarshal(document, &deployment); err != nil {
+			return err
+		}
+		i.workloads = append(i.workloads, workload{typeMeta.Kind, deployment.Name, deployment.Spec.Replicas, &deployment.Spec.Template})
+	case "ReplicaSet":
+		var rs extensions.ReplicaSet
+		if err := yaml.Unmarshal(document, &rs); err != nil {
+			return err
+		}
+		i.workloads = append(i.workloads, workload{typeMeta.Kind, rs.Name, rs.Spec.Replicas, &rs.Spec.Template})
+	case "ReplicationController":
+		var rc api.ReplicationController
+		if err := yaml.Unmarshal(document, &rc); err != nil {
+			return err
+		}
+		if rc.Spec.Template == nil {
+			i.warnf("ReplicationController %s has no pod template, ignoring it", rc.Name)
+			return nil
+		}
+		i.workloads = append(i.workloads, workload{typeMeta.Kind, rc.Name, rc.Spec.Replicas, rc.Spec.Template})
+	case "Service":
+		var svc api.Service
+		if err := yaml.Unmarshal(document, &svc); err != nil {
+			return err
+		}
+		i.services = append(i.services, svc)
+	case "ConfigMap":
+		var configMap api.ConfigMap
+		if err := yaml.Unmarshal(document, &configMap); err != nil {
+			return err
+		}
+		i.configMaps[configMap.Name] = configMap.Data
+	case "":
+		return fmt.Errorf("Invalid manifest, no kind given: %s", document)
+	default:
+		i.warnf("%s objects cannot be imported, ignoring it", typeMeta.Kind)
+	}
+
+	return nil
+}
+
+func (i *importer) config() (*config.Config, error) {
+	cfg := &config.Config{
+		Version:  "2",
+		Services: config.RawServiceMap{},
+		Volumes:  map[string]*config.VolumeConfig{},
+	}
+
+	matched := map[string]bool{}
+	for _, w := range i.workloads {
+		if w.replicas > 1 {
+			i.warnf("%s %s runs %d replicas, compose services run one container", w.kind, w.name, w.replicas)
+		}
+		if len(w.template.Spec.Containers) > 1 {
+			i.warnf("%s %s has %d containers, they are imported as separate services that no longer share a pod", w.kind, w.name, len(w.template.Spec.Containers))
+		}
+
+		services := i.selectingServices(w.template.Labels)
+		for _, svc := range services {
+			matched[svc.Name] = true
+		}
+
+		for _, container := range w.template.Spec.Containers {
+			name := w.name
+			if len(w.template.Spec.Containers) > 1 {
+				name = fmt.Sprintf("%s-%s", w.name, container.Name)
+			}
+			if _, ok := cfg.Services[name]; ok {
+				return nil, fmt.Errorf("Duplicate service %s, imported from %s %s", name, w.kind, w.name)
+			}
+			cfg.Services[name] = i.service(name, w, container, services, cfg.Volumes)
+		}
+	}
+
+	for _, svc := range i.services {
+		if !matched[svc.Name] {
+			i.warnf("Service %s does not select any imported workload, ignoring it", svc.Name)
+		}
+	}
+
+	return cfg, nil
+}
+
+// selectingServices returns the imported Services whose selector matches the specified labels.
+func (i *importer) selectingServices(labels map[string]string) []api.Service {
+	services := []api.Service{}
+	for _, svc := range i.services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		matches := true
+		for key, value := range svc.Spec.Selector {
+			if labels[key] != value {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+func (i *importer) service(name string, w workload, container api.Container, services []api.Service, volumeConfigs map[string]*config.VolumeConfig) config.RawService {
+	service := config.RawService{
+		"image": container.Image,
+	}
+
+	if len(container.Command) > 0 {
+		service["entrypoint"] = toInterfaces(container.Command)
+	}
+	if len(container.Args) > 0 {
+		service["command"] = toInterfaces(container.Args)
+	}
+	if container.WorkingDir != "" {
+		service["working_dir"] = escape(container.WorkingDir)
+	}
+	if container.TTY {
+		service["tty"] = true
+	}
+	if container.Stdin {
+		service["stdin_open"] = true
+	}
+	if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+		service["privileged"] = true
+	}
+
+	switch w.template.Spec.RestartPolicy {
+	case api.RestartPolicyNever:
+		service["restart"] = "no"
+	case api.RestartPolicyOnFailure:
+		service["restart"] = "on-failure"
+	}
+
+	if env := i.environment(name, container); len(env) > 0 {
+		service["environment"] = env
+	}
+
+	ports, expose := i.ports(name, container, services)
+	if len(ports) > 0 {
+		service["ports"] = ports
+	}
+	if len(expose) > 0 {
+		service["expose"] = expose
+	}
+
+	if volumes := i.volumes(name, w.template.Spec.Volumes, container.VolumeMounts, volumeConfigs); len(volumes) > 0 {
+		service["volumes"] = volumes
+	}
+
+	labels := map[interface{}]interface{}{}
+	for key, value := range w.template.Labels {
+		// kompose selects its pods with the service label, it is not part of the compose file.
+		if key == "service" {
+			continue
+		}
+		labels[key] = escape(value)
+	}
+	if len(labels) > 0 {
+		service["labels"] = labels
+	}
+
+	if len(container.Resources.Limits) > 0 || len(container.Resources.Requests) > 0 {
+		i.warnf("Service %s: resource requests and limits are not imported", name)
+	}
+	if container.LivenessProbe != nil || container.ReadinessProbe != nil {
+		i.warnf("Service %s: liveness and readiness probes are not imported", name)
+	}
+
+	return service
+}
+
+func (i *importer) environment(name string, container api.Container) []interface{} {
+	env := []interface{}{}
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom == nil {
+			env = append(env, fmt.Sprintf("%s=%s", envVar.Name, escape(envVar.Value)))
+			continue
+		}
+
+		if ref := envVar.ValueFrom.ConfigMapKeyRef; ref != nil {
+			if data, ok := i.configMaps[ref.Name]; ok {
+				if value, ok := data[ref.Key]; ok {
+					env = append(env, fmt.Sprintf("%s=%s", envVar.Name, escape(value)))
+					continue
+				}
+			}
+			i.warnf("Service %s: environment variable %s references key %s of ConfigMap %s which is not imported", name, envVar.Name, ref.Key, ref.Name)
+			continue
+		}
+
+		i.warnf("Service %s: environment variable %s is not a plain value or a ConfigMap key, ignoring it", name, envVar.Name)
+	}
+	return env
+}
+
+// ports maps the ports of the Services selecting the container to published
+// ports, the other container ports are only exposed.
+func (i *importer) ports(name string, container api.Container, services []api.Service) ([]interface{}, []interface{}) {
+	ports := []interface{}{}
+	expose := []interface{}{}
+
+	published := map[int]bool{}
+	for _, svc := range services {
+		if svc.Spec.Type != "" && svc.Spec.Type != api.ServiceTypeClusterIP {
+			i.warnf("Service %s is of type %s, its ports are published on the host", svc.Name, svc.Spec.Type)
+		}
+		for _, port := range svc.Spec.Ports {
+			targetPort := port.TargetPort.IntValue()
+			if targetPort == 0 {
+				targetPort = containerPortByName(container, port.TargetPort.String())
+			}
+			if targetPort == 0 {
+				targetPort = port.Port
+			}
+			if !hasContainerPort(container, targetPort) {
+				continue
+			}
+			published[targetPort] = true
+			ports = append(ports, fmt.Sprintf("%d:%d%s", port.Port, targetPort, protocolSuffix(port.Protocol)))
+		}
+	}
+
+	for _, port := range container.Ports {
+		if !published[port.ContainerPort] {
+			expose = append(expose, fmt.Sprintf("%d%s", port.ContainerPort, protocolSuffix(port.Protocol)))
+		}
+	}
+
+	return ports, expose
+}
+
+func protocolSuffix(protocol api.Protocol) string {
+	if protocol == api.ProtocolUDP {
+		return "/udp"
+	}
+	return ""
+}
+
+func containerPortByName(container api.Container, name string) int {
+	for _, port := range container.Ports {
+		if port.Name == name {
+			return port.ContainerPort
+		}
+	}
+	return 0
+}
+
+// hasContainerPort returns whether the container declares the port. Containers
+// that declare no port at all are assumed to listen on any of them.
+func hasContainerPort(container api.Container, port int) bool {
+	if len(container.Ports) == 0 {
+		return true
+	}
+	for _, containerPort := range container.Ports {
+		if containerPort.ContainerPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *importer) volumes(name string, podVolumes []api.Volume, mounts []api.VolumeMount, volumeConfigs map[string]*config.VolumeConfig) []interface{} {
+	sources := map[string]api.VolumeSource{}
+	for _, volume := range podVolumes {
+		sources[volume.Name] = volume.VolumeSource
+	}
+
+	volumes := []interface{}{}
+	for _, mount := range mounts {
+		mode := ""
+		if mount.ReadOnly {
+			mode = ":ro"
+		}
+
+		source, ok := sources[mount.Name]
+		switch {
+		case !ok:
+			i.warnf("Service %s: volume %s is not defined in the pod, ignoring it", name, mount.Name)
+		case source.HostPath != nil:
+			volumes = append(volumes, fmt.Sprintf("%s:%s%s", source.HostPath.Path, mount.MountPath, mode))
+		case source.EmptyDir != nil:
+			volumes = append(volumes, mount.MountPath)
+		case source.PersistentVolumeClaim != nil:
+			claim := source.PersistentVolumeClaim.ClaimName
+			volumeConfigs[claim] = &config.VolumeConfig{}
+			volumes = append(volumes, fmt.Sprintf("%s:%s%s", claim, mount.MountPath, mode))
+		default:
+			i.warnf("Service %s: volume %s is neither a hostPath, an emptyDir nor a persistentVolumeClaim, ignoring it", name, mount.Name)
+		}
+	}
+	return volumes
+}
+
+// toInterfaces returns the escaped values as a list of the compose file.
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = escape(value)
+	}
+	return result
+}
+
+// escape escapes the dollar signs of a value, which would be interpolated
+// when the compose file is loaded.
+func escape(value string) string {
+	return strings.Replace(value, "$", "$$", -1)
+}
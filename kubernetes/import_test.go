@@ -0,0 +1,205 @@
+package kubernetes
+
+import (
+	"testing"
+
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/docker/libcompose/config"
+)
+
+var importManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  MODE: production
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        service: web
+        tier: front
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        command: ["nginx"]
+        args: ["-g", "daemon off;"]
+        env:
+        - name: FOO
+          value: bar
+        - name: MODE
+          valueFrom:
+            configMapKeyRef:
+              name: web-config
+              key: MODE
+        ports:
+        - containerPort: 80
+        - containerPort: 443
+        volumeMounts:
+        - name: conf
+          mountPath: /etc/nginx
+          readOnly: true
+        - name: data
+          mountPath: /data
+      volumes:
+      - name: conf
+        hostPath:
+          path: /srv/nginx
+      - name: data
+        persistentVolumeClaim:
+          claimName: web-data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    service: web
+  ports:
+  - port: 8080
+    targetPort: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: orphan
+spec:
+  selector:
+    service: db
+  ports:
+  - port: 5432
+`
+
+func TestImport(t *testing.T) {
+	cfg, warnings, err := Import([]byte(importManifests))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "2", cfg.Version)
+	assert.Equal(t, config.RawService{
+		"image":       "nginx",
+		"entrypoint":  []interface{}{"nginx"},
+		"command":     []interface{}{"-g", "daemon off;"},
+		"environment": []interface{}{"FOO=bar", "MODE=production"},
+		"ports":       []interface{}{"8080:80"},
+		"expose":      []interface{}{"443"},
+		"volumes":     []interface{}{"/srv/nginx:/etc/nginx:ro", "web-data:/data"},
+		"labels":      map[interface{}]interface{}{"tier": "front"},
+	}, cfg.Services["web"])
+	assert.Contains(t, cfg.Volumes, "web-data")
+
+	assert.Equal(t, []string{
+		"Deployment web runs 3 replicas, compose services run one container",
+		"Service orphan does not select any imported workload, ignoring it",
+	}, warnings)
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	cfg, _, err := Import([]byte(importManifests))
+	assert.Nil(t, err)
+
+	data, err := yaml.Marshal(cfg)
+	assert.Nil(t, err)
+
+	_, services, volumes, _, err := config.Merge(config.NewServiceConfigs(), nil, nil, "", data, nil)
+	assert.Nil(t, err)
+
+	web := services["web"]
+	assert.Equal(t, "nginx", web.Image)
+	assert.Equal(t, []string{"nginx"}, []string(web.Entrypoint))
+	assert.Equal(t, []string{"8080:80"}, web.Ports)
+	assert.Contains(t, volumes, "web-data")
+}
+
+func TestImportEscape(t *testing.T) {
+	cfg, _, err := Import([]byte(`---
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  name: worker
+spec:
+  replicas: 1
+  template:
+    metadata:
+      labels:
+        service: worker
+    spec:
+      containers:
+      - name: worker
+        image: busybox
+        command: ["sh", "-c"]
+        args:
+        - |
+          echo $HOME $(MODE)
+          ---
+          echo done
+        env:
+        - name: PROMPT
+          value: $USER$
+        ports:
+        - containerPort: 80
+--- # the next document
+apiVersion: v1
+kind: Service
+metadata:
+  name: worker
+spec:
+  selector:
+    service: worker
+  ports:
+  - port: 80
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"PROMPT=$$USER$$"}, cfg.Services["worker"]["environment"])
+
+	data, err := yaml.Marshal(cfg)
+	assert.Nil(t, err)
+
+	_, services, _, _, err := config.Merge(config.NewServiceConfigs(), nil, nil, "", data, nil)
+	assert.Nil(t, err)
+
+	worker := services["worker"]
+	assert.Equal(t, []string{"sh", "-c"}, []string(worker.Entrypoint))
+	assert.Equal(t, []string{"echo $HOME $(MODE)\n---\necho done\n"}, []string(worker.Command))
+	assert.Equal(t, []string{"PROMPT=$USER$"}, []string(worker.Environment))
+	assert.Equal(t, []string{"80:80"}, worker.Ports)
+}
+
+func TestImportList(t *testing.T) {
+	cfg, warnings, err := Import([]byte(`{
+  "kind": "List",
+  "apiVersion": "v1",
+  "items": [
+    {
+      "kind": "ReplicationController",
+      "apiVersion": "v1",
+      "metadata": {"name": "redis"},
+      "spec": {
+        "replicas": 1,
+        "template": {
+          "metadata": {"labels": {"service": "redis"}},
+          "spec": {"containers": [{"name": "redis", "image": "redis"}]}
+        }
+      }
+    },
+    {"kind": "Ingress", "apiVersion": "extensions/v1beta1", "metadata": {"name": "front"}}
+  ]
+}`))
+	assert.Nil(t, err)
+	assert.Equal(t, config.RawService{"image": "redis"}, cfg.Services["redis"])
+	assert.Equal(t, []string{"Ingress objects cannot be imported, ignoring it"}, warnings)
+}
+
+func TestImportInvalid(t *testing.T) {
+	_, _, err := Import([]byte("metadata:\n  name: web\n"))
+	assert.NotNil(t, err)
+}
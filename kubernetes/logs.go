@@ -0,0 +1,156 @@
+package kubernetes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/watch"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/logger"
+)
+
+// LogOptions holds the options of Logs.
+type LogOptions struct {
+	// Follow keeps streaming the logs, and those of the pods started meanwhile.
+	Follow bool
+	// Since only shows the logs newer than the specified duration, if set.
+	Since time.Duration
+	// Tail only shows the specified number of lines from the end of the logs, all of them if negative.
+	Tail int64
+}
+
+// Logs streams the logs of every container of the pods of the specified
+// services, each one through its own logger created by the factory.
+func Logs(c *client.Client, namespace string, names []string, opts LogOptions, factory logger.Factory) error {
+	selector, err := labels.Parse(fmt.Sprintf("service in (%s)", strings.Join(names, ",")))
+	if err != nil {
+		return err
+	}
+
+	pods, err := c.Pods(namespace).List(api.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+
+	s := &logStreamer{
+		client:    c,
+		namespace: namespace,
+		opts:      opts,
+		factory:   factory,
+		streamed:  map[string]bool{},
+	}
+	for i := range pods.Items {
+		s.stream(&pods.Items[i])
+	}
+
+	if opts.Follow {
+		w, err := c.Pods(namespace).Watch(api.ListOptions{
+			LabelSelector:   selector,
+			ResourceVersion: pods.ResourceVersion,
+		})
+		if err != nil {
+			return err
+		}
+		defer w.Stop()
+
+		for event := range w.ResultChan() {
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			if pod, ok := event.Object.(*api.Pod); ok {
+				s.stream(pod)
+			}
+		}
+	}
+
+	s.wg.Wait()
+	return nil
+}
+
+// logStreamer streams the logs of each pod container once.
+type logStreamer struct {
+	client    *client.Client
+	namespace string
+	opts      LogOptions
+	factory   logger.Factory
+
+	mutex    sync.Mutex
+	streamed map[string]bool
+	wg       sync.WaitGroup
+}
+
+func (s *logStreamer) stream(pod *api.Pod) {
+	// logs can only be fetched once the containers started
+	if pod.Status.Phase == api.PodPending || pod.Status.Phase == api.PodUnknown {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, container := range pod.Spec.Containers {
+		name := pod.Name
+		if len(pod.Spec.Containers) > 1 {
+			name = fmt.Sprintf("%s/%s", pod.Name, container.Name)
+		}
+		if s.streamed[name] {
+			continue
+		}
+		s.streamed[name] = true
+
+		options := &api.PodLogOptions{
+			Container: container.Name,
+			Follow:    s.opts.Follow,
+		}
+		if s.opts.Since > 0 {
+			seconds := int64(s.opts.Since.Seconds())
+			options.SinceSeconds = &seconds
+		}
+		if s.opts.Tail >= 0 {
+			tail := s.opts.Tail
+			options.TailLines = &tail
+		}
+
+		l := s.factory.Create(name)
+		s.wg.Add(1)
+		go func(podName string) {
+			defer s.wg.Done()
+
+			stream, err := s.client.Pods(s.namespace).GetLogs(podName, options).Stream()
+			if err != nil {
+				logrus.Warnf("Failed to get the logs of %s: %v", name, err)
+				return
+			}
+			defer stream.Close()
+
+			if err := copyLines(l, stream); err != nil {
+				logrus.Debugf("Stopped streaming the logs of %s: %v", name, err)
+			}
+		}(pod.Name)
+	}
+}
+
+// copyLines writes each line read from reader to the logger, so that every
+// line gets the logger prefix.
+func copyLines(l logger.Logger, reader io.Reader) error {
+	buffered := bufio.NewReader(reader)
+	for {
+		line, err := buffered.ReadBytes('\n')
+		l.Out(line)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	out []string
+}
+
+func (l *testLogger) Out(bytes []byte) {
+	if len(bytes) > 0 {
+		l.out = append(l.out, string(bytes))
+	}
+}
+
+func (l *testLogger) Err(bytes []byte) {}
+
+func TestCopyLines(t *testing.T) {
+	l := &testLogger{}
+	err := copyLines(l, strings.NewReader("starting\nlistening on :80\nno newline"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"starting\n", "listening on :80\n", "no newline"}, l.out)
+}
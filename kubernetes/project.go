@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/lookup"
+	"github.com/docker/libcompose/project"
+	"github.com/docker/libcompose/project/options"
+)
+
+// NewProject creates a Project with the specified context, whose services
+// run on the Kubernetes cluster of the context client.
+func NewProject(context *Context, parseOptions *config.ParseOptions) (project.APIProject, error) {
+	if context.Client == nil {
+		if context.Config == nil {
+			return nil, fmt.Errorf("No Kubernetes client in the project context")
+		}
+		kubeClient, err := client.New(context.Config)
+		if err != nil {
+			return nil, err
+		}
+		context.Client = kubeClient
+	}
+
+	if context.Namespace == "" {
+		context.Namespace = api.NamespaceDefault
+	}
+
+	opts := context.ConvertOptions
+	if !opts.CreateRC && !opts.CreateDeployment && !opts.CreateDaemonSet && !opts.CreateReplicaSet {
+		context.ConvertOptions.CreateDeployment = true
+	}
+
+	if context.ResourceLookup == nil {
+		context.ResourceLookup = &lookup.RemoteConfigLookup{}
+	}
+
+	if context.EnvironmentLookup == nil {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		context.EnvironmentLookup = &lookup.ComposableEnvLookup{
+			Lookups: []config.EnvironmentLookup{
+				&lookup.EnvfileLookup{
+					Path: filepath.Join(cwd, ".env"),
+				},
+				&lookup.OsEnvLookup{},
+			},
+		}
+	}
+
+	if context.ServiceFactory == nil {
+		context.ServiceFactory = &ServiceFactory{
+			context: context,
+		}
+	}
+
+	runtime := &Project{
+		context: context,
+	}
+	p := project.NewProject(&context.Context, runtime, parseOptions)
+
+	if err := p.Parse(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Project implements project.RuntimeProject and defines Kubernetes runtime specific methods.
+type Project struct {
+	context *Context
+}
+
+// RemoveOrphans implements project.RuntimeProject.RemoveOrphans. It stops and
+// removes the services whose objects were generated for the project but that
+// are no longer part of it.
+func (p *Project) RemoveOrphans(ctx context.Context, projectName string, serviceConfigs *config.ServiceConfigs) error {
+	orphans := map[string]bool{}
+	for _, kind := range Kinds {
+		objects, err := List(p.context.Client, p.context.Namespace, kind)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range objects {
+			meta, err := api.ObjectMetaFor(obj)
+			if err != nil {
+				return err
+			}
+			name := meta.Annotations[ServiceAnnotation]
+			if InProject(*meta, projectName) && !serviceConfigs.Has(name) {
+				orphans[name] = true
+			}
+		}
+	}
+
+	for name := range orphans {
+		logrus.Infof("Removing orphan service %s", name)
+		service := NewComposeService(name, nil, p.context)
+		if err := service.Stop(ctx, 10); err != nil {
+			return err
+		}
+		if err := service.Delete(ctx, options.Delete{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/project"
+)
+
+// apiVersions holds the API version of the kinds listed by Ps.
+var apiVersions = map[string]string{
+	"Secret":                  "v1",
+	"ConfigMap":               "v1",
+	"Service":                 "v1",
+	"ReplicationController":   "v1",
+	"Pod":                     "v1",
+	"Deployment":              "extensions/v1beta1",
+	"DaemonSet":               "extensions/v1beta1",
+	"ReplicaSet":              "extensions/v1beta1",
+	"HorizontalPodAutoscaler": "extensions/v1beta1",
+}
+
+// Listing holds the objects of one kind found in the cluster for a project.
+type Listing struct {
+	Kind    string
+	Objects []runtime.Object
+}
+
+// Ps fetches the objects of the specified kinds ("Pod" included) generated
+// for the specified services.
+func Ps(c *client.Client, namespace string, names []string, kinds []string) ([]Listing, error) {
+	listings := []Listing{}
+	if len(names) == 0 {
+		return listings, nil
+	}
+	for _, kind := range kinds {
+		listing := Listing{Kind: kind}
+
+		if kind == "Pod" {
+			selector, err := labels.Parse(fmt.Sprintf("service in (%s)", strings.Join(names, ",")))
+			if err != nil {
+				return nil, err
+			}
+			pods, err := c.Pods(namespace).List(api.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return nil, err
+			}
+			sort.Sort(podsByName(pods.Items))
+			for i := range pods.Items {
+				listing.Objects = append(listing.Objects, &pods.Items[i])
+			}
+		} else {
+			for _, name := range names {
+				obj, err := Get(c, namespace, kind, name)
+				if errors.IsNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				listing.Objects = append(listing.Objects, obj)
+			}
+		}
+
+		// objects fetched with the client have no type information
+		for _, obj := range listing.Objects {
+			obj.GetObjectKind().SetGroupVersionKind(unversioned.FromAPIVersionAndKind(apiVersions[kind], kind))
+		}
+		listings = append(listings, listing)
+	}
+	return listings, nil
+}
+
+type podsByName []api.Pod
+
+func (p podsByName) Len() int           { return len(p) }
+func (p podsByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p podsByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+
+// Infos returns the table rows describing the objects of the listing. The
+// wide rows hold additional columns. The project is used to tell whether
+// the objects are up to date with the compose file.
+func (l Listing) Infos(p *project.Project, wide bool) project.InfoSet {
+	infos := project.InfoSet{}
+	for _, obj := range l.Objects {
+		var info project.Info
+		switch o := obj.(type) {
+		case *api.Secret:
+			info = dataInfo(o.Name, len(o.Data))
+		case *api.ConfigMap:
+			info = dataInfo(o.Name, len(o.Data))
+		case *api.Service:
+			info = serviceInfo(o, wide)
+		case *api.ReplicationController:
+			info = controllerInfo(o.Name, o.Spec.Replicas, o.Status.Replicas, o.Spec.Template.Spec, labels.SelectorFromSet(o.Spec.Selector).String(), wide)
+		case *extensions.Deployment:
+			info = controllerInfo(o.Name, o.Spec.Replicas, o.Status.Replicas, o.Spec.Template.Spec, selectorString(o.Spec.Selector), wide)
+		case *extensions.ReplicaSet:
+			info = controllerInfo(o.Name, o.Spec.Replicas, o.Status.Replicas, o.Spec.Template.Spec, selectorString(o.Spec.Selector), wide)
+		case *extensions.DaemonSet:
+			info = controllerInfo(o.Name, o.Status.DesiredNumberScheduled, o.Status.CurrentNumberScheduled, o.Spec.Template.Spec, selectorString(o.Spec.Selector), wide)
+		case *extensions.HorizontalPodAutoscaler:
+			info = autoscalerInfo(o, wide)
+		case *api.Pod:
+			info = podInfo(o, wide)
+		default:
+			continue
+		}
+
+		if wide && l.Kind != "Pod" {
+			info = append(info, project.InfoPart{Key: "Up To Date", Value: upToDate(p, obj)})
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func dataInfo(name string, keys int) project.Info {
+	return project.Info{
+		{Key: "Name", Value: name},
+		{Key: "Data", Value: strconv.Itoa(keys)},
+	}
+}
+
+func serviceInfo(svc *api.Service, wide bool) project.Info {
+	ports := []string{}
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+	}
+
+	info := project.Info{
+		{Key: "Name", Value: svc.Name},
+		{Key: "Cluster IP", Value: svc.Spec.ClusterIP},
+		{Key: "Ports", Value: strings.Join(ports, ",")},
+	}
+	if wide {
+		info = append(info, project.InfoPart{Key: "Selector", Value: labels.SelectorFromSet(svc.Spec.Selector).String()})
+	}
+	return info
+}
+
+func controllerInfo(name string, desired, current int, spec api.PodSpec, selector string, wide bool) project.Info {
+	containers := []string{}
+	images := []string{}
+	for _, container := range spec.Containers {
+		containers = append(containers, container.Name)
+		images = append(images, container.Image)
+	}
+
+	info := project.Info{
+		{Key: "Name", Value: name},
+		{Key: "Desired", Value: strconv.Itoa(desired)},
+		{Key: "Current", Value: strconv.Itoa(current)},
+		{Key: "Images", Value: strings.Join(images, ",")},
+	}
+	if wide {
+		info = append(info,
+			project.InfoPart{Key: "Containers", Value: strings.Join(containers, ",")},
+			project.InfoPart{Key: "Selector", Value: selector})
+	}
+	return info
+}
+
+func autoscalerInfo(hpa *extensions.HorizontalPodAutoscaler, wide bool) project.Info {
+	min := 1
+	if hpa.Spec.MinReplicas != nil {
+		min = *hpa.Spec.MinReplicas
+	}
+	target := "<default>"
+	if hpa.Spec.CPUUtilization != nil {
+		target = fmt.Sprintf("%d%%", hpa.Spec.CPUUtilization.TargetPercentage)
+	}
+
+	info := project.Info{
+		{Key: "Name", Value: hpa.Name},
+		{Key: "Reference", Value: fmt.Sprintf("%s/%s", hpa.Spec.ScaleRef.Kind, hpa.Spec.ScaleRef.Name)},
+		{Key: "Min", Value: strconv.Itoa(min)},
+		{Key: "Max", Value: strconv.Itoa(hpa.Spec.MaxReplicas)},
+		{Key: "Desired", Value: strconv.Itoa(hpa.Status.DesiredReplicas)},
+		{Key: "Current", Value: strconv.Itoa(hpa.Status.CurrentReplicas)},
+		{Key: "Target CPU", Value: target},
+	}
+	if wide {
+		current := "<unknown>"
+		if hpa.Status.CurrentCPUUtilizationPercentage != nil {
+			current = fmt.Sprintf("%d%%", *hpa.Status.CurrentCPUUtilizationPercentage)
+		}
+		info = append(info, project.InfoPart{Key: "Current CPU", Value: current})
+	}
+	return info
+}
+
+func podInfo(pod *api.Pod, wide bool) project.Info {
+	restarts := 0
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+
+	info := project.Info{
+		{Key: "Name", Value: pod.Name},
+		{Key: "Service", Value: pod.Labels["service"]},
+		{Key: "Phase", Value: string(pod.Status.Phase)},
+		{Key: "Restarts", Value: strconv.Itoa(restarts)},
+		{Key: "Node", Value: pod.Spec.NodeName},
+		{Key: "Age", Value: age(pod.CreationTimestamp)},
+	}
+	if wide {
+		info = append(info, project.InfoPart{Key: "IP", Value: pod.Status.PodIP})
+	}
+	return info
+}
+
+func selectorString(selector *unversioned.LabelSelector) string {
+	s, err := unversioned.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return ""
+	}
+	return s.String()
+}
+
+// upToDate tells whether an object was generated from the current compose
+// file, "-" if it was not generated by kompose.
+func upToDate(p *project.Project, obj runtime.Object) string {
+	meta, err := api.ObjectMetaFor(obj)
+	if err != nil {
+		return "-"
+	}
+	if _, ok := meta.Annotations[ServiceAnnotation]; !ok {
+		return "-"
+	}
+	if ObjectUpToDate(p, *meta) {
+		return "yes"
+	}
+	return "no"
+}
+
+// age returns the time elapsed since the specified timestamp, in the largest unit.
+func age(timestamp unversioned.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	d := time.Since(timestamp.Time)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+func TestListingInfos(t *testing.T) {
+	p := newTestProject(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+      - "443:443"
+`)
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateRC: true})
+	assert.Nil(t, err)
+
+	svc := objects[0].(*api.Service)
+	svc.Spec.ClusterIP = "10.0.0.12"
+	rc := objects[1].(*api.ReplicationController)
+	rc.Status.Replicas = 1
+
+	services := Listing{Kind: "Service", Objects: []runtime.Object{svc}}
+	assert.Equal(t, ""+
+		"Name  Cluster IP  Ports\n"+
+		"web   10.0.0.12   80/TCP,443/TCP\n", services.Infos(p, false).String(true))
+
+	controllers := Listing{Kind: "ReplicationController", Objects: []runtime.Object{rc}}
+	assert.Equal(t, ""+
+		"Name  Desired  Current  Images\n"+
+		"web   1        1        nginx\n", controllers.Infos(p, false).String(true))
+	assert.Equal(t, ""+
+		"Name  Desired  Current  Images  Containers  Selector     Up To Date\n"+
+		"web   1        1        nginx   web         service=web  yes\n", controllers.Infos(p, true).String(true))
+
+	rc.Annotations[HashAnnotation] = "outdated"
+	assert.Equal(t, "no", controllers.Infos(p, true)[0][6].Value)
+	delete(rc.Annotations, ServiceAnnotation)
+	assert.Equal(t, "-", controllers.Infos(p, true)[0][6].Value)
+}
+
+func TestListingPodInfos(t *testing.T) {
+	pods := Listing{Kind: "Pod", Objects: []runtime.Object{
+		&api.Pod{
+			ObjectMeta: api.ObjectMeta{
+				Name:              "web-x7k2p",
+				Labels:            map[string]string{"service": "web"},
+				CreationTimestamp: unversioned.NewTime(time.Now().Add(-3 * time.Hour)),
+			},
+			Spec: api.PodSpec{NodeName: "node-1"},
+			Status: api.PodStatus{
+				Phase: api.PodRunning,
+				PodIP: "172.17.0.4",
+				ContainerStatuses: []api.ContainerStatus{
+					{Name: "web", RestartCount: 2},
+					{Name: "sidecar", RestartCount: 1},
+				},
+			},
+		},
+	}}
+
+	assert.Equal(t, ""+
+		"Name       Service  Phase    Restarts  Node    Age  IP\n"+
+		"web-x7k2p  web      Running  3         node-1  3h   172.17.0.4\n", pods.Infos(nil, true).String(true))
+}
+
+func TestListingAutoscalerInfos(t *testing.T) {
+	min := 2
+	cpu := 42
+	autoscalers := Listing{Kind: "HorizontalPodAutoscaler", Objects: []runtime.Object{
+		&extensions.HorizontalPodAutoscaler{
+			ObjectMeta: api.ObjectMeta{Name: "web"},
+			Spec: extensions.HorizontalPodAutoscalerSpec{
+				ScaleRef:       extensions.SubresourceReference{Kind: "Deployment", Name: "web"},
+				MinReplicas:    &min,
+				MaxReplicas:    10,
+				CPUUtilization: &extensions.CPUTargetUtilization{TargetPercentage: 75},
+			},
+			Status: extensions.HorizontalPodAutoscalerStatus{
+				CurrentReplicas:                 3,
+				DesiredReplicas:                 4,
+				CurrentCPUUtilizationPercentage: &cpu,
+			},
+		},
+	}}
+
+	assert.Equal(t, ""+
+		"Name  Reference       Min  Max  Desired  Current  Target CPU\n"+
+		"web   Deployment/web  2    10   4        3        75%\n", autoscalers.Infos(nil, false).String(true))
+	assert.Equal(t, "42%", autoscalers.Infos(nil, true)[0][7].Value)
+}
+
+func TestAge(t *testing.T) {
+	assert.Equal(t, "<unknown>", age(unversioned.Time{}))
+	assert.Equal(t, "42s", age(unversioned.NewTime(time.Now().Add(-42*time.Second))))
+	assert.Equal(t, "5m", age(unversioned.NewTime(time.Now().Add(-5*time.Minute))))
+	assert.Equal(t, "2d", age(unversioned.NewTime(time.Now().Add(-50*time.Hour))))
+}
+
+func TestPsWithoutServices(t *testing.T) {
+	listings, err := Ps(nil, api.NamespaceDefault, nil, []string{"Service", "Pod"})
+	assert.Nil(t, err)
+	assert.Empty(t, listings)
+}
@@ -0,0 +1,188 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/config"
+)
+
+// Labels setting the update strategy of the Deployment generated for a
+// service, they override the defaults given in RolloutOptions.
+const (
+	MaxSurgeLabel             = "kompose.deployment.max-surge"
+	MaxUnavailableLabel       = "kompose.deployment.max-unavailable"
+	MinReadySecondsLabel      = "kompose.deployment.min-ready-seconds"
+	RevisionHistoryLimitLabel = "kompose.deployment.revision-history-limit"
+)
+
+// Annotations set by the deployment controller on the ReplicaSets of a Deployment.
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// RolloutOptions holds the default update strategy of the generated
+// Deployments. Empty values are left to the Kubernetes defaults.
+type RolloutOptions struct {
+	MaxSurge             string
+	MaxUnavailable       string
+	MinReadySeconds      string
+	RevisionHistoryLimit string
+}
+
+// Revision describes a revision of a Deployment, that is one of its ReplicaSets.
+type Revision struct {
+	Number      int64
+	ChangeCause string
+	Images      []string
+}
+
+// SetRollout sets the update strategy of a Deployment from the labels of the
+// service, falling back to its update_config, then to the specified defaults.
+func SetRollout(deployment *extensions.Deployment, name string, service *config.ServiceConfig, defaults RolloutOptions) error {
+	defaults, err := updateRollout(name, service, defaults)
+	if err != nil {
+		return err
+	}
+
+	value := func(label, defaultValue string) string {
+		if v, ok := service.Labels[label]; ok {
+			return v
+		}
+		return defaultValue
+	}
+
+	maxSurge := value(MaxSurgeLabel, defaults.MaxSurge)
+	maxUnavailable := value(MaxUnavailableLabel, defaults.MaxUnavailable)
+	if maxSurge != "" || maxUnavailable != "" {
+		rollingUpdate := &extensions.RollingUpdateDeployment{}
+		if maxSurge != "" {
+			surge, err := parseIntOrPercent(maxSurge)
+			if err != nil {
+				return fmt.Errorf("Invalid max surge %s for service %s", maxSurge, name)
+			}
+			rollingUpdate.MaxSurge = surge
+		}
+		if maxUnavailable != "" {
+			unavailable, err := parseIntOrPercent(maxUnavailable)
+			if err != nil {
+				return fmt.Errorf("Invalid max unavailable %s for service %s", maxUnavailable, name)
+			}
+			rollingUpdate.MaxUnavailable = unavailable
+		}
+		deployment.Spec.Strategy = extensions.DeploymentStrategy{
+			Type:          extensions.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: rollingUpdate,
+		}
+	}
+
+	if minReadySeconds := value(MinReadySecondsLabel, defaults.MinReadySeconds); minReadySeconds != "" {
+		seconds, err := strconv.Atoi(minReadySeconds)
+		if err != nil || seconds < 0 {
+			return fmt.Errorf("Invalid min ready seconds %s for service %s", minReadySeconds, name)
+		}
+		deployment.Spec.MinReadySeconds = seconds
+	}
+
+	if revisionHistoryLimit := value(RevisionHistoryLimitLabel, defaults.RevisionHistoryLimit); revisionHistoryLimit != "" {
+		limit, err := strconv.Atoi(revisionHistoryLimit)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("Invalid revision history limit %s for service %s", revisionHistoryLimit, name)
+		}
+		deployment.Spec.RevisionHistoryLimit = &limit
+	}
+
+	return nil
+}
+
+// parseIntOrPercent parses a number of pods, absolute ("1") or relative ("25%").
+func parseIntOrPercent(value string) (intstr.IntOrString, error) {
+	if strings.HasSuffix(value, "%") {
+		if _, err := strconv.Atoi(strings.TrimSuffix(value, "%")); err != nil {
+			return intstr.IntOrString{}, err
+		}
+		return intstr.FromString(value), nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return intstr.IntOrString{}, err
+	}
+	return intstr.FromInt(i), nil
+}
+
+// RolloutStatus returns a message describing the progress of the rollout of
+// a Deployment and whether the rollout is complete.
+func RolloutStatus(deployment *extensions.Deployment) (string, bool) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "Waiting for deployment spec update to be observed...", false
+	}
+	if deployment.Status.UpdatedReplicas < deployment.Spec.Replicas {
+		return fmt.Sprintf("Waiting for rollout to finish: %d out of %d new replicas have been updated...", deployment.Status.UpdatedReplicas, deployment.Spec.Replicas), false
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for rollout to finish: %d old replicas are pending termination...", deployment.Status.Replicas-deployment.Status.UpdatedReplicas), false
+	}
+	if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for rollout to finish: %d of %d updated replicas are available...", deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas), false
+	}
+	return fmt.Sprintf("deployment %s successfully rolled out", deployment.Name), true
+}
+
+// RolloutHistory returns the revisions of a Deployment, oldest first.
+func RolloutHistory(c *client.Client, namespace string, deployment *extensions.Deployment) ([]Revision, error) {
+	selector, err := unversioned.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := c.Extensions().ReplicaSets(namespace).List(api.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []Revision{}
+	for _, rs := range replicaSets.Items {
+		number, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		revision := Revision{
+			Number:      number,
+			ChangeCause: rs.Annotations[changeCauseAnnotation],
+		}
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			revision.Images = append(revision.Images, container.Image)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.Sort(byNumber(revisions))
+	return revisions, nil
+}
+
+type byNumber []Revision
+
+func (r byNumber) Len() int           { return len(r) }
+func (r byNumber) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byNumber) Less(i, j int) bool { return r[i].Number < r[j].Number }
+
+// RolloutUndo rolls a Deployment back to the specified revision, 0 for the
+// previous one.
+func RolloutUndo(c *client.Client, namespace, name string, revision int64) error {
+	return c.Extensions().Deployments(namespace).Rollback(&extensions.DeploymentRollback{
+		Name: name,
+		RollbackTo: extensions.RollbackConfig{
+			Revision: revision,
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/docker/libcompose/config"
+)
+
+func TestSetRollout(t *testing.T) {
+	service := &config.ServiceConfig{
+		Image: "nginx",
+		Labels: map[string]string{
+			MaxSurgeLabel:        "25%",
+			MinReadySecondsLabel: "10",
+			"tier":               "front",
+		},
+	}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+	deployment := Deployment("web", service, template)
+
+	err = SetRollout(deployment, "web", service, RolloutOptions{
+		MaxSurge:             "1",
+		MaxUnavailable:       "0",
+		RevisionHistoryLimit: "5",
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, extensions.RollingUpdateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+	assert.Equal(t, intstr.FromString("25%"), deployment.Spec.Strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, intstr.FromInt(0), deployment.Spec.Strategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, 10, deployment.Spec.MinReadySeconds)
+	assert.Equal(t, 5, *deployment.Spec.RevisionHistoryLimit)
+
+	// the kompose labels configure the conversion, they are not copied to the objects
+	assert.Equal(t, map[string]string{"service": "web", "tier": "front"}, deployment.Labels)
+}
+
+func TestSetRolloutDefaults(t *testing.T) {
+	service := &config.ServiceConfig{Image: "nginx"}
+	template, err := PodTemplate("web", service)
+	assert.Nil(t, err)
+	deployment := Deployment("web", service, template)
+
+	assert.Nil(t, SetRollout(deployment, "web", service, RolloutOptions{}))
+	assert.Equal(t, extensions.DeploymentStrategy{}, deployment.Spec.Strategy)
+	assert.Equal(t, 0, deployment.Spec.MinReadySeconds)
+	assert.Nil(t, deployment.Spec.RevisionHistoryLimit)
+}
+
+func TestSetRolloutInvalid(t *testing.T) {
+	for _, labels := range []map[string]string{
+		{MaxSurgeLabel: "many"},
+		{MaxUnavailableLabel: "ten%"},
+		{MinReadySecondsLabel: "-1"},
+		{RevisionHistoryLimitLabel: "all"},
+	} {
+		service := &config.ServiceConfig{Image: "nginx", Labels: labels}
+		err := SetRollout(&extensions.Deployment{}, "web", service, RolloutOptions{})
+		assert.NotNil(t, err, "%v", labels)
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	deployment := &extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{Name: "web", Generation: 2},
+		Spec:       extensions.DeploymentSpec{Replicas: 3},
+		Status:     extensions.DeploymentStatus{ObservedGeneration: 1},
+	}
+
+	message, done := RolloutStatus(deployment)
+	assert.False(t, done)
+	assert.Equal(t, "Waiting for deployment spec update to be observed...", message)
+
+	deployment.Status = extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 2}
+	message, done = RolloutStatus(deployment)
+	assert.False(t, done)
+	assert.Equal(t, "Waiting for rollout to finish: 2 out of 3 new replicas have been updated...", message)
+
+	deployment.Status = extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3}
+	message, done = RolloutStatus(deployment)
+	assert.False(t, done)
+	assert.Equal(t, "Waiting for rollout to finish: 1 old replicas are pending termination...", message)
+
+	deployment.Status = extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}
+	message, done = RolloutStatus(deployment)
+	assert.True(t, done)
+	assert.Equal(t, "deployment web successfully rolled out", message)
+}
@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// ScalableKinds lists the kinds of controllers Scale updates.
+var ScalableKinds = []string{"Deployment", "ReplicaSet", "ReplicationController"}
+
+// Scale sets the number of replicas of every controller generated for the
+// specified service, and returns the kinds of the controllers it scaled.
+func Scale(c *client.Client, namespace, name string, replicas int) ([]string, error) {
+	scaled := []string{}
+	for _, kind := range ScalableKinds {
+		scale, err := c.Extensions().Scales(namespace).Get(kind, name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return scaled, err
+		}
+
+		scale.Spec.Replicas = replicas
+		if _, err := c.Extensions().Scales(namespace).Update(kind, scale); err != nil {
+			return scaled, err
+		}
+		scaled = append(scaled, kind)
+	}
+
+	if len(scaled) == 0 {
+		return nil, fmt.Errorf("No deployment, replica set or replication controller found for service %s", name)
+	}
+	return scaled, nil
+}
+
+// WaitForScale waits until the controller of the specified kind has the
+// specified number of ready pods, or the timeout expires. The pods of the other
+// controllers of the service, matched by the same selector, are not counted.
+func WaitForScale(c *client.Client, namespace, kind, name string, replicas int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		scale, err := c.Extensions().Scales(namespace).Get(kind, name)
+		if err != nil {
+			return err
+		}
+
+		if scale.Status.Replicas == replicas {
+			selector, err := unversioned.LabelSelectorAsSelector(scale.Status.Selector)
+			if err != nil {
+				return err
+			}
+			pods, err := c.Pods(namespace).List(api.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return err
+			}
+			owned := []api.Pod{}
+			for _, pod := range pods.Items {
+				if ownedBy(&pod, kind, name) {
+					owned = append(owned, pod)
+				}
+			}
+			if countReady(owned) == replicas {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Timed out waiting for %s %s to have %d ready replicas", kind, name, replicas)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func countReady(pods []api.Pod) int {
+	ready := 0
+	for i := range pods {
+		if isReady(&pods[i]) {
+			ready++
+		}
+	}
+	return ready
+}
+
+// createdByAnnotation references the controller that created a pod.
+const createdByAnnotation = "kubernetes.io/created-by"
+
+// ownedBy returns whether a pod was created by the controller of the specified
+// kind and name. The pods of a Deployment are created by its ReplicaSets, named
+// after it and the hash of their pod template.
+func ownedBy(pod *api.Pod, kind, name string) bool {
+	var ref api.SerializedReference
+	if err := json.Unmarshal([]byte(pod.Annotations[createdByAnnotation]), &ref); err != nil {
+		return false
+	}
+	if kind == "Deployment" {
+		hash, ok := pod.Labels[extensions.DefaultDeploymentUniqueLabelKey]
+		return ok && ref.Reference.Kind == "ReplicaSet" && ref.Reference.Name == name+"-"+hash
+	}
+	return ref.Reference.Kind == kind && ref.Reference.Name == name
+}
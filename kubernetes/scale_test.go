@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+func TestCountReady(t *testing.T) {
+	ready := []api.PodCondition{{Type: api.PodReady, Status: api.ConditionTrue}}
+	deleted := unversioned.Now()
+	pods := []api.Pod{
+		{Status: api.PodStatus{Phase: api.PodRunning, Conditions: ready}},
+		{Status: api.PodStatus{Phase: api.PodRunning, Conditions: []api.PodCondition{{Type: api.PodReady, Status: api.ConditionFalse}}}},
+		{Status: api.PodStatus{Phase: api.PodPending}},
+		{ObjectMeta: api.ObjectMeta{DeletionTimestamp: &deleted}, Status: api.PodStatus{Phase: api.PodRunning, Conditions: ready}},
+		{Status: api.PodStatus{Phase: api.PodRunning, Conditions: ready}},
+	}
+
+	assert.Equal(t, 2, countReady(pods))
+	assert.Equal(t, 0, countReady(nil))
+}
+
+func TestOwnedBy(t *testing.T) {
+	createdBy := func(kind, name string) map[string]string {
+		return map[string]string{createdByAnnotation: `{"kind":"SerializedReference","apiVersion":"v1","reference":{"kind":"` + kind + `","namespace":"default","name":"` + name + `"}}`}
+	}
+	rcPod := &api.Pod{ObjectMeta: api.ObjectMeta{
+		Labels:      map[string]string{"service": "web"},
+		Annotations: createdBy("ReplicationController", "web"),
+	}}
+	deploymentPod := &api.Pod{ObjectMeta: api.ObjectMeta{
+		Labels:      map[string]string{"service": "web", "pod-template-hash": "2035384211"},
+		Annotations: createdBy("ReplicaSet", "web-2035384211"),
+	}}
+	replicaSetPod := &api.Pod{ObjectMeta: api.ObjectMeta{
+		Labels:      map[string]string{"service": "web"},
+		Annotations: createdBy("ReplicaSet", "web"),
+	}}
+
+	assert.True(t, ownedBy(rcPod, "ReplicationController", "web"))
+	assert.False(t, ownedBy(rcPod, "Deployment", "web"))
+	assert.False(t, ownedBy(rcPod, "ReplicationController", "db"))
+	assert.True(t, ownedBy(deploymentPod, "Deployment", "web"))
+	assert.False(t, ownedBy(deploymentPod, "ReplicaSet", "web"))
+	assert.True(t, ownedBy(replicaSetPod, "ReplicaSet", "web"))
+	assert.False(t, ownedBy(replicaSetPod, "Deployment", "web"))
+	assert.False(t, ownedBy(&api.Pod{}, "ReplicationController", "web"))
+}
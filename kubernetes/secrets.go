@@ -0,0 +1,181 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+)
+
+// The vendored API cannot mount a single file of a volume (subPath). The
+// files of the secrets of a service are the keys of a Secret named after the
+// service, and the files of its configs the keys of a ConfigMap, each mounted
+// as a directory: the files of a Secret or a ConfigMap must be in the same
+// directory, and the secrets and the configs in different ones.
+
+// fileObjects generates the Secret holding the files of the secrets of the
+// specified service and the ConfigMap holding the files of its configs.
+// External secrets and configs are not generated, they are ignored.
+func fileObjects(p *project.Project, name string, service *config.ServiceConfig) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	warnExternal(name, "secret", service.Secrets, p.Secrets)
+	warnExternal(name, "config", service.Configs, p.Configs)
+
+	secrets, _, err := serviceFiles(name, "secret", service.Secrets, p.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	if len(secrets) > 0 {
+		secret := &api.Secret{
+			TypeMeta: unversioned.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: api.ObjectMeta{
+				Name:   name,
+				Labels: Labels(name, service),
+			},
+			Data: map[string][]byte{},
+			Type: api.SecretTypeOpaque,
+		}
+		for _, file := range secrets {
+			data, err := readFile(name, "secret", file, p.Secrets[file.Source])
+			if err != nil {
+				return nil, err
+			}
+			secret.Data[path.Base(file.Path())] = data
+		}
+		objects = append(objects, secret)
+	}
+
+	configs, _, err := serviceFiles(name, "config", service.Configs, p.Configs)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) > 0 {
+		configMap := &api.ConfigMap{
+			TypeMeta: unversioned.TypeMeta{
+				Kind:       "ConfigMap",
+				APIVersion: "v1",
+			},
+			ObjectMeta: api.ObjectMeta{
+				Name:   name,
+				Labels: Labels(name, service),
+			},
+			Data: map[string]string{},
+		}
+		for _, file := range configs {
+			data, err := readFile(name, "config", file, p.Configs[file.Source])
+			if err != nil {
+				return nil, err
+			}
+			configMap.Data[path.Base(file.Path())] = string(data)
+		}
+		objects = append(objects, configMap)
+	}
+
+	return objects, nil
+}
+
+// fileVolumes returns the volumes of the Secret and the ConfigMap generated
+// for the specified service and their read-only mounts.
+func fileVolumes(p *project.Project, name string, service *config.ServiceConfig) ([]api.VolumeMount, []api.Volume, error) {
+	var volumeMounts []api.VolumeMount
+	var volumes []api.Volume
+
+	secrets, secretsDir, err := serviceFiles(name, "secret", service.Secrets, p.Secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(secrets) > 0 {
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      name + "-secrets",
+			ReadOnly:  true,
+			MountPath: secretsDir,
+		})
+		volumes = append(volumes, api.Volume{
+			Name: name + "-secrets",
+			VolumeSource: api.VolumeSource{
+				Secret: &api.SecretVolumeSource{SecretName: name},
+			},
+		})
+	}
+
+	configs, configsDir, err := serviceFiles(name, "config", service.Configs, p.Configs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(configs) > 0 {
+		if len(secrets) > 0 && configsDir == secretsDir {
+			return nil, nil, fmt.Errorf("Service %s mounts secrets and configs in %s, they must be mounted in different directories", name, secretsDir)
+		}
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      name + "-configs",
+			ReadOnly:  true,
+			MountPath: configsDir,
+		})
+		volumes = append(volumes, api.Volume{
+			Name: name + "-configs",
+			VolumeSource: api.VolumeSource{
+				ConfigMap: &api.ConfigMapVolumeSource{
+					LocalObjectReference: api.LocalObjectReference{Name: name},
+				},
+			},
+		})
+	}
+
+	return volumeMounts, volumes, nil
+}
+
+// serviceFiles returns the secrets or configs of the specified service that
+// are not external, and the directory where their files are mounted.
+func serviceFiles(name, kind string, files []config.ServiceFile, definitions map[string]*config.FileConfig) ([]config.ServiceFile, string, error) {
+	var serviceFiles []config.ServiceFile
+	dir := ""
+	for _, file := range files {
+		definition, ok := definitions[file.Source]
+		if !ok {
+			return nil, "", fmt.Errorf("Service %s uses the undefined %s %s", name, kind, file.Source)
+		}
+		if definition.External.External {
+			continue
+		}
+
+		fileDir := path.Dir(file.Path())
+		if dir != "" && fileDir != dir {
+			return nil, "", fmt.Errorf("Service %s mounts %ss in %s and %s, they must be mounted in the same directory", name, kind, dir, fileDir)
+		}
+		dir = fileDir
+		serviceFiles = append(serviceFiles, file)
+	}
+	return serviceFiles, dir, nil
+}
+
+// warnExternal warns that the external secrets or configs of the specified
+// service are not mounted.
+func warnExternal(name, kind string, files []config.ServiceFile, definitions map[string]*config.FileConfig) {
+	for _, file := range files {
+		if definition, ok := definitions[file.Source]; ok && definition.External.External {
+			logrus.Warnf("Service %s uses the external %s %s, which is not mounted", name, kind, file.Source)
+		}
+	}
+}
+
+// readFile returns the content of the file of a secret or a config.
+func readFile(name, kind string, file config.ServiceFile, definition *config.FileConfig) ([]byte, error) {
+	if definition.File == "" {
+		return nil, fmt.Errorf("The %s %s of service %s has no file", kind, file.Source, name)
+	}
+	if file.UID != "" || file.GID != "" || file.Mode != nil {
+		logrus.Warnf("The uid, gid and mode of the %s %s of service %s are not supported", kind, file.Source, name)
+	}
+	return ioutil.ReadFile(definition.File)
+}
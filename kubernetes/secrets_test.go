@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+func TestTransformSecretsAndConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kompose")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "password.txt"), []byte("s3cr3t"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "nginx.conf"), []byte("worker_processes 1;"), 0644))
+
+	p := newTestProject(t, fmt.Sprintf(`
+version: '3.3'
+services:
+  web:
+    image: nginx
+    secrets:
+      - password
+      - token
+    configs:
+      - source: nginx
+        target: /etc/nginx/nginx.conf
+secrets:
+  password:
+    file: %s
+  token:
+    external: true
+configs:
+  nginx:
+    file: %s
+`, filepath.Join(dir, "password.txt"), filepath.Join(dir, "nginx.conf")))
+
+	objects, err := (&Converter{}).Transform(p, ConvertOptions{CreateDeployment: true})
+	assert.Nil(t, err)
+
+	kinds := []string{}
+	for _, obj := range objects {
+		kinds = append(kinds, Kind(obj)+"/"+Name(obj))
+	}
+	assert.Equal(t, []string{"Secret/web", "ConfigMap/web", "Deployment/web"}, kinds)
+
+	secret := objects[0].(*api.Secret)
+	assert.Equal(t, map[string][]byte{"password": []byte("s3cr3t")}, secret.Data)
+	assert.Equal(t, "web", secret.Annotations[ServiceAnnotation])
+
+	configMap := objects[1].(*api.ConfigMap)
+	assert.Equal(t, map[string]string{"nginx.conf": "worker_processes 1;"}, configMap.Data)
+
+	spec := objects[2].(*extensions.Deployment).Spec.Template.Spec
+	assert.Equal(t, []api.VolumeMount{
+		{Name: "web-secrets", ReadOnly: true, MountPath: "/run/secrets"},
+		{Name: "web-configs", ReadOnly: true, MountPath: "/etc/nginx"},
+	}, spec.Containers[0].VolumeMounts)
+	assert.Equal(t, "web", spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, "web", spec.Volumes[1].ConfigMap.Name)
+}
+
+func TestTransformSecretsInvalid(t *testing.T) {
+	for _, composeFile := range []string{`
+version: '3.3'
+services:
+  web:
+    image: nginx
+    secrets:
+      - password
+`, `
+version: '3.3'
+services:
+  web:
+    image: nginx
+    secrets:
+      - password
+    configs:
+      - nginx
+secrets:
+  password:
+    file: /etc/password
+configs:
+  nginx:
+    file: /etc/nginx.conf
+`, `
+version: '3.3'
+services:
+  web:
+    image: nginx
+    secrets:
+      - password
+      - source: certificate
+        target: /etc/ssl/web.pem
+secrets:
+  password:
+    file: /etc/password
+  certificate:
+    file: /etc/ssl/web.pem
+`} {
+		_, err := (&Converter{}).Transform(newTestProject(t, composeFile), ConvertOptions{CreateDeployment: true})
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,516 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/term"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+	"github.com/docker/libcompose/project/events"
+	"github.com/docker/libcompose/project/options"
+)
+
+// this ensures ComposeService implements project.Service
+var _ project.Service = &ComposeService{}
+
+// ComposeService is a project.Service implementation running a compose service as
+// the Kubernetes objects generated for it.
+type ComposeService struct {
+	name          string
+	project       *project.Project
+	serviceConfig *config.ServiceConfig
+	client        *client.Client
+	namespace     string
+	context       *Context
+}
+
+// NewComposeService creates a service
+func NewComposeService(name string, serviceConfig *config.ServiceConfig, context *Context) *ComposeService {
+	return &ComposeService{
+		name:          name,
+		project:       context.Project,
+		serviceConfig: serviceConfig,
+		client:        context.Client,
+		namespace:     context.Namespace,
+		context:       context,
+	}
+}
+
+// Name returns the service name.
+func (s *ComposeService) Name() string {
+	return s.name
+}
+
+// Config returns the configuration of the service (config.ServiceConfig).
+func (s *ComposeService) Config() *config.ServiceConfig {
+	return s.serviceConfig
+}
+
+// DependentServices returns the dependent services (as an array of ServiceRelationship) of the service.
+func (s *ComposeService) DependentServices() []project.ServiceRelationship {
+	return project.DefaultDependentServices(s.project, s)
+}
+
+// Create implements Service.Create. It creates the objects generated for the
+// service, and replaces the existing ones generated from another
+// configuration. The number of replicas of the controllers is kept.
+func (s *ComposeService) Create(ctx context.Context, options options.Create) error {
+	objects, err := s.objects()
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		kind, name := Kind(obj), Name(obj)
+		live, err := Get(s.client, s.namespace, kind, name)
+		if errors.IsNotFound(err) {
+			logrus.Infof("Creating %s %s", kind, name)
+			if _, err := Create(s.client, s.namespace, obj); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		meta, err := api.ObjectMetaFor(live)
+		if err != nil {
+			return err
+		}
+		if options.NoRecreate || (!options.ForceRecreate && ObjectUpToDate(s.project, *meta)) {
+			continue
+		}
+
+		if err := prepareUpdate(obj, live); err != nil {
+			return err
+		}
+		logrus.Infof("Recreating %s %s", kind, name)
+		if _, err := Update(s.client, s.namespace, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objects generates the objects of the service, from the group of services
+// whose containers run in its pod: the objects annotated with the service and
+// the controllers of the pod, which every service of the group creates.
+func (s *ComposeService) objects() ([]runtime.Object, error) {
+	group, err := ServiceGroup(s.project, s.name)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := GroupObjects(s.project, group, s.context.ConvertOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceObjects := []runtime.Object{}
+	for _, obj := range objects {
+		meta, err := api.ObjectMetaFor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if meta.Annotations[ServiceAnnotation] == s.name || meta.Annotations[GroupAnnotation] != "" {
+			serviceObjects = append(serviceObjects, obj)
+		}
+	}
+	return serviceObjects, nil
+}
+
+// prepareUpdate copies to the desired object the fields of the live one that
+// must be kept when replacing it.
+func prepareUpdate(desired, live runtime.Object) error {
+	desiredMeta, err := api.ObjectMetaFor(desired)
+	if err != nil {
+		return err
+	}
+	liveMeta, err := api.ObjectMetaFor(live)
+	if err != nil {
+		return err
+	}
+	desiredMeta.ResourceVersion = liveMeta.ResourceVersion
+
+	switch d := desired.(type) {
+	case *api.Service:
+		if l, ok := live.(*api.Service); ok {
+			d.Spec.ClusterIP = l.Spec.ClusterIP
+		}
+	case *api.ReplicationController:
+		if l, ok := live.(*api.ReplicationController); ok {
+			d.Spec.Replicas = l.Spec.Replicas
+		}
+	case *extensions.Deployment:
+		if l, ok := live.(*extensions.Deployment); ok {
+			d.Spec.Replicas = l.Spec.Replicas
+		}
+	case *extensions.ReplicaSet:
+		if l, ok := live.(*extensions.ReplicaSet); ok {
+			d.Spec.Replicas = l.Spec.Replicas
+		}
+	}
+	return nil
+}
+
+// Up implements Service.Up. It creates the objects of the service and starts
+// its stopped controllers.
+func (s *ComposeService) Up(ctx context.Context, options options.Up) error {
+	if err := s.Create(ctx, options.Create); err != nil {
+		return err
+	}
+	return s.Start(ctx)
+}
+
+// Start implements Service.Start. It scales the stopped controllers of the
+// service to its number of replicas, and creates again the autoscaler Stop
+// removed.
+func (s *ComposeService) Start(ctx context.Context) error {
+	for _, kind := range ScalableKinds {
+		scale, err := s.client.Extensions().Scales(s.namespace).Get(kind, s.name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if scale.Spec.Replicas > 0 {
+			continue
+		}
+
+		scale.Spec.Replicas = Replicas(s.serviceConfig)
+		if _, err := s.client.Extensions().Scales(s.namespace).Update(kind, scale); err != nil {
+			return err
+		}
+	}
+
+	objects, err := s.objects()
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if kind, name := Kind(obj), Name(obj); kind == "HorizontalPodAutoscaler" {
+			_, err := Get(s.client, s.namespace, kind, name)
+			if !errors.IsNotFound(err) {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			logrus.Infof("Creating %s %s", kind, name)
+			if _, err := Create(s.client, s.namespace, obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stop implements Service.Stop. It scales the controllers of the service to
+// zero replicas and waits for their pods to terminate. The autoscaler of the
+// service, which would scale them back, is removed; daemon sets, which cannot
+// be scaled, are removed with their pods.
+func (s *ComposeService) Stop(ctx context.Context, timeout int) error {
+	if err := s.delete("HorizontalPodAutoscaler"); err != nil {
+		return err
+	}
+
+	for _, kind := range ScalableKinds {
+		scale, err := s.client.Extensions().Scales(s.namespace).Get(kind, s.name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if scale.Spec.Replicas > 0 {
+			scale.Spec.Replicas = 0
+			if _, err := s.client.Extensions().Scales(s.namespace).Update(kind, scale); err != nil {
+				return err
+			}
+		}
+		if err := WaitForScale(s.client, s.namespace, kind, s.name, 0, time.Duration(timeout)*time.Second); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.client.Extensions().DaemonSets(s.namespace).Get(s.name); err == nil {
+		if err := s.delete("DaemonSet"); err != nil {
+			return err
+		}
+		return s.deletePods(int64(timeout))
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Restart implements Service.Restart. It deletes the pods of the service,
+// their controllers replace them.
+func (s *ComposeService) Restart(ctx context.Context, timeout int) error {
+	return s.deletePods(int64(timeout))
+}
+
+// Kill implements Service.Kill. Pods cannot be sent signals, they are deleted
+// immediately instead, and replaced by their controllers.
+func (s *ComposeService) Kill(ctx context.Context, signal string) error {
+	if signal != "SIGKILL" {
+		logrus.Warnf("Kubernetes cannot send %s to the pods of %s, they are deleted immediately", signal, s.name)
+	}
+	return s.deletePods(0)
+}
+
+// Delete implements Service.Delete. It removes the objects of the service,
+// the autoscaler and controllers first. It fails if some pods of the service
+// are running, the service must be stopped first.
+func (s *ComposeService) Delete(ctx context.Context, options options.Delete) error {
+	containers, err := s.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if running, _ := c.IsRunning(ctx); running {
+			return fmt.Errorf("Service %s is running, stop it before removing it", s.name)
+		}
+	}
+
+	for i := len(Kinds) - 1; i >= 0; i-- {
+		if err := s.delete(Kinds[i]); err != nil {
+			return err
+		}
+	}
+
+	// the replica sets of the deployment are not removed with it
+	selector := labels.SelectorFromSet(labels.Set{"service": s.name})
+	replicaSets, err := s.client.Extensions().ReplicaSets(s.namespace).List(api.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	for _, rs := range replicaSets.Items {
+		if _, ok := rs.Annotations[revisionAnnotation]; !ok {
+			continue
+		}
+		if err := s.client.Extensions().ReplicaSets(s.namespace).Delete(rs.Name, nil); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ComposeService) delete(kind string) error {
+	err := Delete(s.client, s.namespace, kind, s.name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err == nil {
+		logrus.Infof("Removed %s %s", kind, s.name)
+	}
+	return err
+}
+
+func (s *ComposeService) pods() ([]api.Pod, error) {
+	pods, err := s.client.Pods(s.namespace).List(api.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"service": s.name}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(podsByName(pods.Items))
+	return pods.Items, nil
+}
+
+func (s *ComposeService) deletePods(gracePeriod int64) error {
+	pods, err := s.pods()
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		if err := s.client.Pods(s.namespace).Delete(pod.Name, api.NewDeleteOptions(gracePeriod)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Containers implements Service.Containers. It returns the controllers of the service.
+func (s *ComposeService) Containers(ctx context.Context) ([]project.Container, error) {
+	containers := []project.Container{}
+	for _, kind := range ScalableKinds {
+		scale, err := s.client.Extensions().Scales(s.namespace).Get(kind, s.name)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, &Controller{Kind: kind, name: s.name, replicas: scale.Spec.Replicas})
+	}
+
+	ds, err := s.client.Extensions().DaemonSets(s.namespace).Get(s.name)
+	if err == nil {
+		containers = append(containers, &Controller{Kind: "DaemonSet", name: s.name, replicas: ds.Status.DesiredNumberScheduled})
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// Info implements Service.Info. It describes the pods of the service.
+func (s *ComposeService) Info(ctx context.Context, qFlag bool) (project.InfoSet, error) {
+	pods, err := s.pods()
+	if err != nil {
+		return nil, err
+	}
+
+	result := project.InfoSet{}
+	for _, pod := range pods {
+		if qFlag {
+			result = append(result, project.Info{{Key: "Id", Value: pod.Name}})
+			continue
+		}
+
+		command := []string{}
+		ports := []string{}
+		for _, container := range pod.Spec.Containers {
+			command = append(command, strings.Join(append(container.Command, container.Args...), " "))
+			for _, port := range container.Ports {
+				ports = append(ports, podPort(pod.Status.PodIP, port))
+			}
+		}
+
+		result = append(result, project.Info{
+			{Key: "Name", Value: pod.Name},
+			{Key: "Command", Value: strings.Join(command, ", ")},
+			{Key: "State", Value: string(pod.Status.Phase)},
+			{Key: "Ports", Value: strings.Join(ports, ", ")},
+		})
+	}
+	return result, nil
+}
+
+func podPort(ip string, port api.ContainerPort) string {
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = api.ProtocolTCP
+	}
+	if ip == "" {
+		return fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+	}
+	return fmt.Sprintf("%s:%d/%s", ip, port.ContainerPort, protocol)
+}
+
+// Log implements Service.Log. It streams the logs of the pods of the service.
+func (s *ComposeService) Log(ctx context.Context, follow bool) error {
+	return Logs(s.client, s.namespace, []string{s.name}, LogOptions{Follow: follow, Tail: -1}, s.context.LoggerFactory)
+}
+
+// Run implements Service.Run. It runs the command in a one-off pod created
+// from the service.
+func (s *ComposeService) Run(ctx context.Context, commandParts []string) (int, error) {
+	pod, err := OneOffPod(s.name, s.serviceConfig, commandParts)
+	if err != nil {
+		return 0, err
+	}
+	if s.context.Config == nil {
+		return 0, fmt.Errorf("No Kubernetes client configuration in the project context to attach to the pod of service %s", s.name)
+	}
+	stdinFd, tty := term.GetFdInfo(os.Stdin)
+	if tty {
+		state, err := term.SetRawTerminal(stdinFd)
+		if err != nil {
+			return 0, err
+		}
+		defer term.RestoreTerminal(stdinFd, state)
+	}
+	return Run(s.client, s.context.Config, s.namespace, pod, os.Stdin, os.Stdout, os.Stderr, tty)
+}
+
+// Scale implements Service.Scale. It sets the number of replicas of the
+// controllers of the service.
+func (s *ComposeService) Scale(ctx context.Context, count int, timeout int) error {
+	_, err := Scale(s.client, s.namespace, s.name, count)
+	return err
+}
+
+// Events implements Service.Events. It watches the pods of the service until
+// the context is done.
+func (s *ComposeService) Events(ctx context.Context, messages chan events.ContainerEvent) error {
+	w, err := s.client.Pods(s.namespace).Watch(api.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"service": s.name}),
+	})
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			pod, ok := event.Object.(*api.Pod)
+			if !ok {
+				continue
+			}
+			messages <- events.ContainerEvent{
+				Service: s.name,
+				Event:   strings.ToLower(string(event.Type)),
+				ID:      pod.Name,
+				Time:    time.Now(),
+				Attributes: map[string]string{
+					"name":  pod.Name,
+					"phase": string(pod.Status.Phase),
+				},
+				Type: "pod",
+			}
+		}
+	}
+}
+
+// Build is not supported, images are pulled by the cluster nodes.
+func (s *ComposeService) Build(ctx context.Context, buildOptions options.Build) error {
+	return project.ErrUnsupported
+}
+
+// Pull is not supported, images are pulled by the cluster nodes.
+func (s *ComposeService) Pull(ctx context.Context) error {
+	return project.ErrUnsupported
+}
+
+// Pause is not supported by Kubernetes.
+func (s *ComposeService) Pause(ctx context.Context) error {
+	return project.ErrUnsupported
+}
+
+// Unpause is not supported by Kubernetes.
+func (s *ComposeService) Unpause(ctx context.Context) error {
+	return project.ErrUnsupported
+}
+
+// RemoveImage implements Service.RemoveImage. Removing images is not
+// supported, the images live on the cluster nodes.
+func (s *ComposeService) RemoveImage(ctx context.Context, imageType options.ImageType) error {
+	if imageType == "" {
+		return nil
+	}
+	return project.ErrUnsupported
+}
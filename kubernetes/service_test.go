@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+
+	restclient "k8s.io/kubernetes/pkg/client/restclient"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/docker/libcompose/project"
+	"github.com/docker/libcompose/project/events"
+	"github.com/docker/libcompose/project/options"
+)
+
+const notFound = `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`
+
+// emptyCluster fakes an API server without any object, recording the
+// requests it receives.
+type emptyCluster struct {
+	mutex    sync.Mutex
+	requests []string
+	// created holds the kind and name of the created objects.
+	created []string
+}
+
+func (e *emptyCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mutex.Lock()
+	e.requests = append(e.requests, r.Method+" "+r.URL.Path)
+	e.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == "POST" {
+		body, _ := ioutil.ReadAll(r.Body)
+		var obj struct {
+			Kind     string
+			Metadata struct{ Name string }
+		}
+		json.Unmarshal(body, &obj)
+		e.mutex.Lock()
+		e.created = append(e.created, obj.Kind+"/"+obj.Metadata.Name)
+		e.mutex.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(notFound))
+}
+
+func TestProjectUp(t *testing.T) {
+	cluster := &emptyCluster{}
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	p, err := NewProject(&Context{
+		Context: project.Context{
+			ProjectName:  "test",
+			ComposeFiles: []string{"docker-compose.yml"},
+			ComposeBytes: [][]byte{[]byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80"
+`)},
+		},
+		Client: client.NewOrDie(&restclient.Config{Host: server.URL}),
+	}, nil)
+	assert.Nil(t, err)
+
+	listener := make(chan events.Event, 16)
+	p.AddListener(listener)
+
+	assert.Nil(t, p.Up(context.Background(), options.Up{}))
+	close(listener)
+
+	eventTypes := []events.EventType{}
+	for event := range listener {
+		eventTypes = append(eventTypes, event.EventType)
+	}
+	assert.Equal(t, []events.EventType{events.ProjectUpStart, events.ServiceUpStart, events.ServiceUp, events.ProjectUpDone}, eventTypes)
+
+	assert.Contains(t, cluster.requests, "POST /api/v1/namespaces/default/services")
+	assert.Contains(t, cluster.requests, "POST /apis/extensions/v1beta1/namespaces/default/deployments")
+}
+
+func TestProjectUpGroup(t *testing.T) {
+	cluster := &emptyCluster{}
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	p, err := NewProject(&Context{
+		Context: project.Context{
+			ProjectName:  "test",
+			ComposeFiles: []string{"docker-compose.yml"},
+			ComposeBytes: [][]byte{[]byte(`
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80"
+  log:
+    image: fluentd
+    x-kompose-sidecar-of: web
+`)},
+		},
+		Client: client.NewOrDie(&restclient.Config{Host: server.URL}),
+	}, nil)
+	assert.Nil(t, err)
+
+	assert.Nil(t, p.Up(context.Background(), options.Up{}, "log"))
+	assert.Equal(t, []string{"Deployment/web"}, cluster.created)
+}
+
+func TestPrepareUpdate(t *testing.T) {
+	desired := &extensions.Deployment{Spec: extensions.DeploymentSpec{Replicas: 1}}
+	live := &extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{ResourceVersion: "42"},
+		Spec:       extensions.DeploymentSpec{Replicas: 3},
+	}
+	assert.Nil(t, prepareUpdate(desired, live))
+	assert.Equal(t, "42", desired.ResourceVersion)
+	assert.Equal(t, 3, desired.Spec.Replicas)
+
+	svc := &api.Service{}
+	assert.Nil(t, prepareUpdate(svc, &api.Service{Spec: api.ServiceSpec{ClusterIP: "10.0.0.12"}}))
+	assert.Equal(t, "10.0.0.12", svc.Spec.ClusterIP)
+}
+
+func TestPodPort(t *testing.T) {
+	assert.Equal(t, "172.17.0.4:80/TCP", podPort("172.17.0.4", api.ContainerPort{ContainerPort: 80}))
+	assert.Equal(t, "53/UDP", podPort("", api.ContainerPort{ContainerPort: 53, Protocol: api.ProtocolUDP}))
+}
@@ -0,0 +1,179 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/project"
+)
+
+// ConvertOptions holds the options that select which objects a Transformer generates.
+type ConvertOptions struct {
+	CreateRC         bool
+	CreateDeployment bool
+	CreateDaemonSet  bool
+	CreateReplicaSet bool
+	Rollout          RolloutOptions
+}
+
+// Transformer defines methods to convert a compose project into the objects
+// of a target platform (plain Kubernetes, OpenShift, …).
+type Transformer interface {
+	Transform(p *project.Project, opts ConvertOptions) ([]runtime.Object, error)
+}
+
+// this ensures Converter implements Transformer
+var _ Transformer = &Converter{}
+
+// Converter implements Transformer and generates plain Kubernetes objects.
+type Converter struct {
+}
+
+// Transform implements Transformer.Transform. For each group of services
+// sharing a pod it generates the objects returned by GroupObjects.
+func (c *Converter) Transform(p *project.Project, opts ConvertOptions) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	groups, err := Groups(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		groupObjects, err := GroupObjects(p, group, opts)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, groupObjects...)
+	}
+
+	return objects, nil
+}
+
+// ServiceObjects generates the objects of the specified service: a Secret
+// and a ConfigMap (if the service has secrets and configs), a Service (if the
+// service has ports), the controllers selected in opts and a
+// HorizontalPodAutoscaler (if the service is autoscaled), all annotated with
+// their compose source.
+func ServiceObjects(p *project.Project, name string, serviceConfig *config.ServiceConfig, opts ConvertOptions) ([]runtime.Object, error) {
+	objects, err := fileObjects(p, name, serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := Service(name, serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(svc.Spec.Ports) > 0 {
+		objects = append(objects, svc)
+	}
+
+	template, err := servicePodTemplate(p, name, serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	controllers, err := controllerObjects(name, serviceConfig, template, opts)
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, controllers...)
+
+	if err := Annotate(Annotations(p, name, serviceConfig), objects...); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// servicePodTemplate returns the pod template of the specified service,
+// mounting its secrets and configs.
+func servicePodTemplate(p *project.Project, name string, serviceConfig *config.ServiceConfig) (api.PodTemplateSpec, error) {
+	template, err := PodTemplate(name, serviceConfig)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+
+	volumeMounts, volumes, err := fileVolumes(p, name, serviceConfig)
+	if err != nil {
+		return api.PodTemplateSpec{}, err
+	}
+	container := &template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, volumeMounts...)
+	template.Spec.Volumes = append(template.Spec.Volumes, volumes...)
+	return template, nil
+}
+
+// controllerObjects generates the controllers selected in opts running the
+// specified pod template, only a DaemonSet for the global services, and a
+// HorizontalPodAutoscaler if the service is autoscaled.
+func controllerObjects(name string, serviceConfig *config.ServiceConfig, template api.PodTemplateSpec, opts ConvertOptions) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	if Global(serviceConfig) {
+		opts = ConvertOptions{CreateDaemonSet: true}
+	}
+
+	// the autoscaler targets the deployment, else the replica set, else
+	// the replication controller
+	var scaled runtime.Object
+	if opts.CreateRC {
+		rc := ReplicationController(name, serviceConfig, template)
+		objects = append(objects, rc)
+		scaled = rc
+	}
+	if opts.CreateDeployment {
+		deployment := Deployment(name, serviceConfig, template)
+		if err := SetRollout(deployment, name, serviceConfig, opts.Rollout); err != nil {
+			return nil, err
+		}
+		objects = append(objects, deployment)
+		scaled = deployment
+	}
+	if opts.CreateDaemonSet {
+		objects = append(objects, DaemonSet(name, serviceConfig, template))
+	}
+	if opts.CreateReplicaSet {
+		rs := ReplicaSet(name, serviceConfig, template)
+		objects = append(objects, rs)
+		if !opts.CreateDeployment {
+			scaled = rs
+		}
+	}
+
+	if Autoscaled(serviceConfig) {
+		if scaled == nil {
+			return nil, fmt.Errorf("Service %s can only be autoscaled with a deployment, a replica set or a replication controller", name)
+		}
+		hpa, err := HorizontalPodAutoscaler(name, serviceConfig, scaled)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, hpa)
+	}
+	return objects, nil
+}
+
+// ServiceNames returns the sorted names of the enabled services of the
+// specified project and of the services they depend on, so that conversions
+// always generate objects in the same order.
+func ServiceNames(p *project.Project) []string {
+	return p.EnabledServices()
+}
+
+// Kind returns the kind of the specified object.
+func Kind(obj runtime.Object) string {
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+// Name returns the name of the specified object, or an empty string if the
+// object has no metadata.
+func Name(obj runtime.Object) string {
+	meta, err := api.ObjectMetaFor(obj)
+	if err != nil {
+		return ""
+	}
+	return meta.Name
+}
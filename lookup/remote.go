@@ -0,0 +1,285 @@
+package lookup
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/docker/libcompose/config"
+)
+
+// defaultGitPath is the file read in a git repository whose source names no
+// file.
+const defaultGitPath = "docker-compose.yml"
+
+// gitSource is a file of a git repository, named like docker build contexts:
+// <repository>#<ref>:<path>, like git://github.com/org/repo.git#v1:base.yml.
+// The ref defaults to the HEAD of the repository, and the path to
+// docker-compose.yml.
+type gitSource struct {
+	repository string
+	ref        string
+	path       string
+}
+
+// parseGitSource parses the specified file as a file of a git repository:
+// a git:// or git@ repository, or an http(s), ssh or file URL of a .git
+// repository.
+func parseGitSource(file string) (gitSource, bool) {
+	parts := strings.SplitN(file, "#", 2)
+	repository := parts[0]
+
+	isGit := strings.HasPrefix(repository, "git://") || strings.HasPrefix(repository, "git@")
+	for _, scheme := range []string{"http://", "https://", "ssh://", "file://"} {
+		if strings.HasPrefix(repository, scheme) && strings.HasSuffix(repository, ".git") {
+			isGit = true
+		}
+	}
+	if !isGit {
+		return gitSource{}, false
+	}
+
+	source := gitSource{repository: repository}
+	if len(parts) == 2 {
+		fragment := strings.SplitN(parts[1], ":", 2)
+		source.ref = fragment[0]
+		if len(fragment) == 2 {
+			source.path = fragment[1]
+		}
+	}
+	source.path = strings.TrimPrefix(path.Clean("/"+source.path), "/")
+	if source.path == "" {
+		source.path = defaultGitPath
+	}
+	return source, true
+}
+
+func (s gitSource) String() string {
+	return fmt.Sprintf("%s#%s:%s", s.repository, s.ref, s.path)
+}
+
+func isHTTP(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// IsRemote returns whether the specified file is an HTTP(S) URL or a file of
+// a git repository, that RemoteConfigLookup reads.
+func IsRemote(file string) bool {
+	_, isGit := parseGitSource(file)
+	return isGit || isHTTP(file)
+}
+
+// RemoteConfigLookup is a ResourceLookup reading the files of HTTP(S) URLs
+// and of git repositories, and the local files with its Fallback, a
+// FileConfigLookup by default. The paths relative to a remote file, like the
+// files it extends or its env files, resolve against its URL or its directory
+// in the repository.
+//
+// The repositories are cloned in CacheDir, ~/.kompose/git by default, once
+// per repository and ref, where the host paths of the volumes of their files
+// resolve: the clones are kept for the containers mounting them, and fetched
+// again by the next lookups.
+type RemoteConfigLookup struct {
+	Fallback config.ResourceLookup
+	Client   *http.Client
+	CacheDir string
+
+	mu        sync.Mutex
+	checkouts map[string]string
+}
+
+func (l *RemoteConfigLookup) fallback() config.ResourceLookup {
+	if l.Fallback == nil {
+		return &FileConfigLookup{}
+	}
+	return l.Fallback
+}
+
+// resolve returns the name of the specified file relative to a remote file,
+// or the file itself.
+func resolve(file, relativeTo string) string {
+	if IsRemote(file) {
+		return file
+	}
+
+	if source, ok := parseGitSource(relativeTo); ok {
+		source.path = strings.TrimPrefix(path.Join(path.Dir(source.path), file), "/")
+		if path.IsAbs(file) {
+			source.path = strings.TrimPrefix(path.Clean(file), "/")
+		}
+		return source.String()
+	}
+
+	if isHTTP(relativeTo) {
+		base, err := url.Parse(relativeTo)
+		if err != nil {
+			return file
+		}
+		reference, err := url.Parse(file)
+		if err != nil {
+			return file
+		}
+		return base.ResolveReference(reference).String()
+	}
+
+	return file
+}
+
+// Lookup implements config.ResourceLookup.Lookup. It returns the content of
+// the specified file, relative to relativeTo, and its name.
+func (l *RemoteConfigLookup) Lookup(file, relativeTo string) ([]byte, string, error) {
+	resolved := resolve(file, relativeTo)
+
+	if source, ok := parseGitSource(resolved); ok {
+		dir, err := l.checkout(source)
+		if err != nil {
+			return nil, "", err
+		}
+		logrus.Debugf("Reading file %s", source)
+		bytes, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(source.path)))
+		return bytes, source.String(), err
+	}
+
+	if isHTTP(resolved) {
+		bytes, err := l.fetch(resolved)
+		return bytes, resolved, err
+	}
+
+	return l.fallback().Lookup(file, relativeTo)
+}
+
+// ResolvePath implements config.ResourceLookup.ResolvePath. The host paths of
+// the volumes of the files of git repositories resolve in their checkout,
+// the ones of HTTP files are left unchanged.
+func (l *RemoteConfigLookup) ResolvePath(volume, inFile string) string {
+	if isHTTP(inFile) {
+		return volume
+	}
+
+	source, ok := parseGitSource(inFile)
+	if !ok {
+		return l.fallback().ResolvePath(volume, inFile)
+	}
+
+	vs := strings.SplitN(volume, ":", 2)
+	if len(vs) != 2 || filepath.IsAbs(vs[0]) {
+		return volume
+	}
+	dir, err := l.checkout(source)
+	if err != nil {
+		logrus.Errorf("Failed to resolve %s in %s: %v", vs[0], inFile, err)
+		return volume
+	}
+	vs[0] = filepath.Join(dir, filepath.FromSlash(path.Dir(source.path)), vs[0])
+	return strings.Join(vs, ":")
+}
+
+// fetch returns the content of an HTTP(S) URL.
+func (l *RemoteConfigLookup) fetch(file string) ([]byte, error) {
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	logrus.Debugf("Fetching file %s", file)
+	response, err := client.Get(file)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to fetch %s: %s", file, response.Status)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+func (l *RemoteConfigLookup) cacheDir() string {
+	if l.CacheDir == "" {
+		return filepath.Join(homedir.Get(), ".kompose", "git")
+	}
+	return l.CacheDir
+}
+
+// checkout returns the directory where the ref of the repository of the
+// specified source is checked out: cloning it in the cache the first time,
+// and fetching it the first time it is looked up afterwards.
+func (l *RemoteConfigLookup) checkout(source gitSource) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := source.repository + "#" + source.ref
+	if dir, ok := l.checkouts[key]; ok {
+		return dir, nil
+	}
+
+	dir := filepath.Join(l.cacheDir(), fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+	cloned := false
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		logrus.Debugf("Fetching %s", key)
+		if _, err := git(dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+			logrus.Warnf("Failed to fetch %s, using its cached clone: %v", source.repository, err)
+		}
+	} else {
+		if err := os.MkdirAll(l.cacheDir(), 0755); err != nil {
+			return "", err
+		}
+		logrus.Debugf("Cloning %s", key)
+		if _, err := git("", "clone", "--quiet", "--no-checkout", source.repository, dir); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("Failed to clone %s: %v", source.repository, err)
+		}
+		cloned = true
+	}
+
+	// the ref is a branch, whose remote one is the fetched one, a tag or a
+	// commit
+	ref := source.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit := ""
+	for _, candidate := range []string{"origin/" + ref, ref} {
+		if output, err := git(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			commit = strings.TrimSpace(output)
+			break
+		}
+	}
+	if commit == "" {
+		if cloned {
+			os.RemoveAll(dir)
+		}
+		return "", fmt.Errorf("Failed to find ref %s in %s", ref, source.repository)
+	}
+	if _, err := git(dir, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+		return "", fmt.Errorf("Failed to check out %s of %s: %v", ref, source.repository, err)
+	}
+
+	if l.checkouts == nil {
+		l.checkouts = map[string]string{}
+	}
+	l.checkouts[key] = dir
+	return dir, nil
+}
+
+// git runs a git command in the specified directory, the current one if
+// empty, and returns its output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
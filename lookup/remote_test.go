@@ -0,0 +1,182 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveRemote(t *testing.T) {
+	valids := map[input]string{
+		input{"base.yml", "https://example.com/compose/docker-compose.yml"}:                "https://example.com/compose/base.yml",
+		input{"../base.yml", "https://example.com/compose/docker-compose.yml"}:             "https://example.com/base.yml",
+		input{"/base.yml", "https://example.com/compose/docker-compose.yml"}:               "https://example.com/base.yml",
+		input{"base.yml", "git://example.com/repo.git"}:                                    "git://example.com/repo.git#:base.yml",
+		input{"base.yml", "git://example.com/repo.git#v1:compose/docker-compose.yml"}:      "git://example.com/repo.git#v1:compose/base.yml",
+		input{"../base.yml", "git@example.com:org/repo.git#v1:compose/docker-compose.yml"}: "git@example.com:org/repo.git#v1:base.yml",
+		input{"/base.yml", "https://example.com/repo.git#v1:compose/docker-compose.yml"}:   "https://example.com/repo.git#v1:base.yml",
+		input{"https://example.com/base.yml", "git://example.com/repo.git"}:                "https://example.com/base.yml",
+		input{"base.yml", "/tmp/docker-compose.yml"}:                                       "base.yml",
+	}
+
+	for valid, expected := range valids {
+		if resolved := resolve(valid.file, valid.relativeTo); resolved != expected {
+			t.Fatalf("Expected %s relative to %s to resolve to %s, got %s", valid.file, valid.relativeTo, expected, resolved)
+		}
+	}
+}
+
+func TestRemoteLookupHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/compose/docker-compose.yml":
+			w.Write([]byte("content1"))
+		case "/compose/base.yml":
+			w.Write([]byte("content2"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	remoteConfigLookup := RemoteConfigLookup{}
+
+	file := server.URL + "/compose/docker-compose.yml"
+	valids := map[input]string{
+		input{file, ""}:           "content1",
+		input{"base.yml", file}:   "content2",
+		input{"./base.yml", file}: "content2",
+	}
+	for valid, expectedContent := range valids {
+		out, _, err := remoteConfigLookup.Lookup(valid.file, valid.relativeTo)
+		if err != nil || string(out) != expectedContent {
+			t.Fatalf("Expected %s to contains '%s', got %s, %v.", valid.file, expectedContent, out, err)
+		}
+	}
+
+	_, _, err := remoteConfigLookup.Lookup("missing.yml", file)
+	expectedError := "Failed to fetch " + server.URL + "/compose/missing.yml: 404 Not Found"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error with '%s', got '%v'", expectedError, err)
+	}
+
+	if volume := remoteConfigLookup.ResolvePath("./data:/data", file); volume != "./data:/data" {
+		t.Fatalf("Expected the volume of an HTTP file to be unchanged, got %s", volume)
+	}
+}
+
+func TestRemoteLookupGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpFolder, err := ioutil.TempDir("", "lookup-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpFolder)
+
+	work := filepath.Join(tmpFolder, "work")
+	repository := filepath.Join(tmpFolder, "repo.git")
+	files := map[string]string{
+		"docker-compose.yml":     "content1",
+		"compose/base.yml":       "content2",
+		"compose/common/env.yml": "content3",
+	}
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(work, file)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(work, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commands := [][]string{
+		{"init", "--quiet", work},
+		{"-C", work, "add", "-A"},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "v1"},
+		{"-C", work, "tag", "v1"},
+		{"-C", work, "checkout", "--quiet", "-b", "next"},
+		{"-C", work, "rm", "--quiet", "compose/base.yml"},
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "next"},
+		{"-C", work, "checkout", "--quiet", "-B", "main", "v1"},
+		{"clone", "--quiet", "--bare", work, repository},
+	}
+	for _, args := range commands {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("Failed to run git %s: %v: %s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	cache := filepath.Join(tmpFolder, "cache")
+	remoteConfigLookup := RemoteConfigLookup{CacheDir: cache}
+
+	url := "file://" + repository
+	valids := map[input]string{
+		input{url, ""}:                                               "content1",
+		input{url + "#v1:compose/base.yml", ""}:                      "content2",
+		input{"common/env.yml", url + "#v1:compose/x"}:               "content3",
+		input{"../docker-compose.yml", url + "#v1:compose/base.yml"}: "content1",
+		input{"/compose/base.yml", url + "#next:docker-compose.yml"}: "",
+	}
+	for valid, expectedContent := range valids {
+		out, _, err := remoteConfigLookup.Lookup(valid.file, valid.relativeTo)
+		if expectedContent == "" {
+			if err == nil {
+				t.Fatalf("Expected %s relative to %s to not exist", valid.file, valid.relativeTo)
+			}
+			continue
+		}
+		if err != nil || string(out) != expectedContent {
+			t.Fatalf("Expected %s to contains '%s', got %s, %v.", valid.file, expectedContent, out, err)
+		}
+	}
+
+	_, resolved, err := remoteConfigLookup.Lookup("base.yml", url+"#v1:compose/docker-compose.yml")
+	if err != nil || resolved != url+"#v1:compose/base.yml" {
+		t.Fatalf("Expected base.yml to resolve to %s, got %s, %v", url+"#v1:compose/base.yml", resolved, err)
+	}
+
+	_, _, err = remoteConfigLookup.Lookup(url+"#missing", "")
+	expectedError := "Failed to find ref missing in " + url
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf("Expected error with '%s', got '%v'", expectedError, err)
+	}
+
+	volume := remoteConfigLookup.ResolvePath("./data:/data", url+"#v1:compose/base.yml")
+	dir := strings.SplitN(volume, ":", 2)[0]
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "..", "base.yml")); err != nil || string(content) != "content2" {
+		t.Fatalf("Expected %s to resolve in the checkout of v1, got %s", volume, dir)
+	}
+	if volume := remoteConfigLookup.ResolvePath("/data:/data", url); volume != "/data:/data" {
+		t.Fatalf("Expected an absolute volume to be unchanged, got %s", volume)
+	}
+	if !strings.HasPrefix(dir, cache) {
+		t.Fatalf("Expected %s to be cloned in %s", url, cache)
+	}
+
+	// the cached clones are fetched again by the next lookups
+	if out, _, err := remoteConfigLookup.Lookup(url+"#main", ""); err != nil || string(out) != "content1" {
+		t.Fatalf("Expected main to contain 'content1', got %s, %v", out, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(work, "docker-compose.yml"), []byte("content4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"-C", work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-a", "-m", "v2"},
+		{"-C", work, "push", "--quiet", repository, "main"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("Failed to run git %s: %v: %s", strings.Join(args, " "), err, output)
+		}
+	}
+	nextLookup := RemoteConfigLookup{CacheDir: cache}
+	if out, _, err := nextLookup.Lookup(url+"#main", ""); err != nil || string(out) != "content4" {
+		t.Fatalf("Expected the cached clone of main to be fetched, got %s, %v", out, err)
+	}
+}
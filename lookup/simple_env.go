@@ -12,12 +12,13 @@ type OsEnvLookup struct {
 }
 
 // Lookup creates a string slice of string containing a "docker-friendly" environment string
-// in the form of 'key=value'. It gets environment values using os.Getenv.
-// If the os environment variable does not exists, the slice is empty. serviceName and config
-// are not used at all in this implementation.
+// in the form of 'key=value'. It gets environment values using os.LookupEnv.
+// If the os environment variable does not exists, the slice is empty, a variable set to
+// an empty string is returned as 'key='. serviceName and config are not used at all in
+// this implementation.
 func (o *OsEnvLookup) Lookup(key, serviceName string, config *config.ServiceConfig) []string {
-	ret := os.Getenv(key)
-	if ret == "" {
+	ret, ok := os.LookupEnv(key)
+	if !ok {
 		return []string{}
 	}
 	return []string{fmt.Sprintf("%s=%s", key, ret)}
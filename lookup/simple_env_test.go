@@ -1,7 +1,10 @@
 package lookup
 
 import (
+	"os"
 	"testing"
+
+	"github.com/docker/libcompose/config"
 )
 
 func TestOsEnvLookup(t *testing.T) {
@@ -26,3 +29,38 @@ func TestOsEnvLookup(t *testing.T) {
 		t.Fatalf("Expected envs to be empty, but was %v", envs)
 	}
 }
+
+func TestOsEnvLookupEmptyVariable(t *testing.T) {
+	os.Setenv("KOMPOSE_TEST_EMPTY", "")
+	defer os.Unsetenv("KOMPOSE_TEST_EMPTY")
+	os.Unsetenv("KOMPOSE_TEST_UNSET")
+
+	envs := (&OsEnvLookup{}).Lookup("KOMPOSE_TEST_EMPTY", "anything", nil)
+	if len(envs) != 1 || envs[0] != "KOMPOSE_TEST_EMPTY=" {
+		t.Fatalf("Expected envs to be [KOMPOSE_TEST_EMPTY=], but was %v", envs)
+	}
+
+	rawServiceMap := config.RawServiceMap{
+		"web": {
+			"image":       "nginx${KOMPOSE_TEST_EMPTY-:latest}",
+			"hostname":    "${KOMPOSE_TEST_EMPTY:-web}",
+			"domainname":  "${KOMPOSE_TEST_UNSET-example.com}",
+			"working_dir": "/srv${KOMPOSE_TEST_EMPTY?must be set}",
+		},
+	}
+	if err := config.Interpolate(&OsEnvLookup{}, &rawServiceMap); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"image":       "nginx",
+		"hostname":    "web",
+		"domainname":  "example.com",
+		"working_dir": "/srv",
+	}
+	for key, value := range expected {
+		if rawServiceMap["web"][key] != value {
+			t.Fatalf("Expected %s to be %q, but was %q", key, value, rawServiceMap["web"][key])
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package openshift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/kubernetes"
+	"github.com/docker/libcompose/project"
+)
+
+// this ensures Converter implements kubernetes.Transformer
+var _ kubernetes.Transformer = &Converter{}
+
+// Converter implements kubernetes.Transformer and generates OpenShift objects.
+// Each service gets a Service (if it has ports), a DeploymentConfig triggered
+// by changes of its ImageStream, a Route for each published port and, for
+// services built from a git remote, a BuildConfig.
+type Converter struct {
+}
+
+// Transform implements kubernetes.Transformer.Transform. The controller
+// selection in opts is ignored, DeploymentConfigs replace them on OpenShift.
+func (c *Converter) Transform(p *project.Project, opts kubernetes.ConvertOptions) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+
+	for _, name := range kubernetes.ServiceNames(p) {
+		service, _ := p.ServiceConfigs.Get(name)
+		first := len(objects)
+
+		svc, err := kubernetes.Service(name, service)
+		if err != nil {
+			return nil, err
+		}
+		if len(svc.Spec.Ports) > 0 {
+			objects = append(objects, svc)
+		}
+
+		template, err := kubernetes.PodTemplate(name, service)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := imageTag(service.Image)
+		objects = append(objects, ImageStreamFor(name, service))
+
+		if service.Build.Context != "" {
+			if config.IsValidRemote(service.Build.Context) {
+				objects = append(objects, BuildConfigFor(name, service))
+			} else {
+				logrus.Warnf("Service %s is built from a local context (%s), no BuildConfig generated: OpenShift builds need a git remote", name, service.Build.Context)
+			}
+		}
+
+		objects = append(objects, DeploymentConfigFor(name, service, template, tag))
+
+		routes, err := RoutesFor(name, service)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routes {
+			objects = append(objects, route)
+		}
+
+		if err := kubernetes.Annotate(kubernetes.Annotations(p, name, service), objects[first:]...); err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
+// imageTag returns the tag of the specified image reference, "latest" if none is given.
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i+1:], "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// ImageStreamFor creates the ImageStream tracking the image of the specified
+// service. Services with an image import it into the stream, services that
+// are built get an empty stream their BuildConfig pushes to.
+func ImageStreamFor(name string, service *config.ServiceConfig) *ImageStream {
+	is := &ImageStream{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "ImageStream",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: kubernetes.Labels(name, service),
+		},
+	}
+
+	if service.Image != "" {
+		is.Spec.Tags = []TagReference{
+			{
+				Name: imageTag(service.Image),
+				From: &api.ObjectReference{
+					Kind: "DockerImage",
+					Name: service.Image,
+				},
+			},
+		}
+	}
+
+	return is
+}
+
+// DeploymentConfigFor creates the DeploymentConfig of the specified service,
+// redeployed each time the tag of its ImageStream changes.
+func DeploymentConfigFor(name string, service *config.ServiceConfig, template api.PodTemplateSpec, tag string) *DeploymentConfig {
+	if template.Spec.Containers[0].Image == "" {
+		template.Spec.Containers[0].Image = fmt.Sprintf("%s:%s", name, tag)
+	}
+
+	return &DeploymentConfig{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "DeploymentConfig",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: kubernetes.Labels(name, service),
+		},
+		Spec: DeploymentConfigSpec{
+			Replicas: kubernetes.Replicas(service),
+			Selector: map[string]string{"service": name},
+			Template: &template,
+			Strategy: DeploymentStrategy{
+				Type: "Rolling",
+			},
+			Triggers: []DeploymentTriggerPolicy{
+				{
+					Type: "ConfigChange",
+				},
+				{
+					Type: "ImageChange",
+					ImageChangeParams: &DeploymentTriggerImageChangeParams{
+						Automatic:      true,
+						ContainerNames: []string{name},
+						From: api.ObjectReference{
+							Kind: "ImageStreamTag",
+							Name: fmt.Sprintf("%s:%s", name, tag),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// RoutesFor creates a Route for each published port of the specified service.
+// The first route is named after the service, the next ones get the port as suffix.
+func RoutesFor(name string, service *config.ServiceConfig) ([]*Route, error) {
+	routes := []*Route{}
+	for _, port := range service.Ports {
+		published, targetPort, protocol, err := kubernetes.ParsePort(name, port)
+		if err != nil {
+			return nil, err
+		}
+		// routes only forward HTTP traffic
+		if published == "" || protocol != api.ProtocolTCP {
+			continue
+		}
+
+		routeName := name
+		if len(routes) > 0 {
+			routeName = fmt.Sprintf("%s-%s", name, published)
+		}
+
+		routes = append(routes, &Route{
+			TypeMeta: unversioned.TypeMeta{
+				Kind:       "Route",
+				APIVersion: "v1",
+			},
+			ObjectMeta: api.ObjectMeta{
+				Name:   routeName,
+				Labels: kubernetes.Labels(name, service),
+			},
+			Spec: RouteSpec{
+				To: api.ObjectReference{
+					Kind: "Service",
+					Name: name,
+				},
+				Port: &RoutePort{
+					TargetPort: intstr.FromInt(targetPort),
+				},
+			},
+		})
+	}
+	return routes, nil
+}
+
+// BuildConfigFor creates the BuildConfig building the specified service from
+// its git remote build context ("url[#ref[:dir]]", like docker build) and
+// pushing the result to the service ImageStream.
+func BuildConfigFor(name string, service *config.ServiceConfig) *BuildConfig {
+	uri, ref, contextDir := parseRemote(service.Build.Context)
+
+	return &BuildConfig{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "BuildConfig",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   name,
+			Labels: kubernetes.Labels(name, service),
+		},
+		Spec: BuildConfigSpec{
+			Triggers: []BuildTriggerPolicy{
+				{Type: "ConfigChange"},
+			},
+			Source: BuildSource{
+				Type: "Git",
+				Git: &GitBuildSource{
+					URI: uri,
+					Ref: ref,
+				},
+				ContextDir: contextDir,
+			},
+			Strategy: BuildStrategy{
+				Type: "Docker",
+				DockerStrategy: &DockerBuildStrategy{
+					DockerfilePath: service.Build.Dockerfile,
+				},
+			},
+			Output: BuildOutput{
+				To: &api.ObjectReference{
+					Kind: "ImageStreamTag",
+					Name: fmt.Sprintf("%s:%s", name, imageTag(service.Image)),
+				},
+			},
+		},
+	}
+}
+
+// parseRemote splits a remote build context into its repository URI, ref and
+// context directory.
+func parseRemote(remote string) (string, string, string) {
+	parts := strings.SplitN(remote, "#", 2)
+	if len(parts) == 1 {
+		return remote, "", ""
+	}
+	fragment := strings.SplitN(parts[1], ":", 2)
+	if len(fragment) == 1 {
+		return parts[0], fragment[0], ""
+	}
+	return parts[0], fragment[0], fragment[1]
+}
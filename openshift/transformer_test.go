@@ -0,0 +1,128 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/api"
+
+	"github.com/docker/libcompose/kubernetes"
+	"github.com/docker/libcompose/project"
+)
+
+func transform(t *testing.T, composeFile string) map[string]interface{} {
+	p := project.NewProject(&project.Context{}, nil, nil)
+	if err := p.Load([]byte(composeFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := (&Converter{}).Transform(p, kubernetes.ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := map[string]interface{}{}
+	for _, obj := range objects {
+		result[kubernetes.Kind(obj)+"/"+kubernetes.Name(obj)] = obj
+	}
+	return result
+}
+
+func TestTransformImage(t *testing.T) {
+	objects := transform(t, `
+version: '2'
+services:
+  web:
+    image: nginx:1.11
+    ports:
+      - "80:80"
+      - "8443:443"
+      - "9000"
+`)
+
+	assert.Equal(t, 5, len(objects))
+	assert.NotNil(t, objects["Service/web"])
+
+	is := objects["ImageStream/web"].(*ImageStream)
+	assert.Equal(t, []TagReference{{
+		Name: "1.11",
+		From: &api.ObjectReference{Kind: "DockerImage", Name: "nginx:1.11"},
+	}}, is.Spec.Tags)
+
+	dc := objects["DeploymentConfig/web"].(*DeploymentConfig)
+	assert.Equal(t, 1, dc.Spec.Replicas)
+	assert.Equal(t, "nginx:1.11", dc.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, 2, len(dc.Spec.Triggers))
+	assert.Equal(t, "ImageChange", dc.Spec.Triggers[1].Type)
+	assert.Equal(t, api.ObjectReference{Kind: "ImageStreamTag", Name: "web:1.11"}, dc.Spec.Triggers[1].ImageChangeParams.From)
+
+	route := objects["Route/web"].(*Route)
+	assert.Equal(t, "web", route.Spec.To.Name)
+	assert.Equal(t, 80, route.Spec.Port.TargetPort.IntValue())
+	route = objects["Route/web-8443"].(*Route)
+	assert.Equal(t, 443, route.Spec.Port.TargetPort.IntValue())
+}
+
+func TestTransformBuild(t *testing.T) {
+	objects := transform(t, `
+version: '2'
+services:
+  app:
+    build:
+      context: https://github.com/docker/compose.git#master:tests
+      dockerfile: Dockerfile.app
+  local:
+    build: .
+`)
+
+	bc := objects["BuildConfig/app"].(*BuildConfig)
+	assert.Equal(t, &GitBuildSource{URI: "https://github.com/docker/compose.git", Ref: "master"}, bc.Spec.Source.Git)
+	assert.Equal(t, "tests", bc.Spec.Source.ContextDir)
+	assert.Equal(t, "Dockerfile.app", bc.Spec.Strategy.DockerStrategy.DockerfilePath)
+	assert.Equal(t, &api.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}, bc.Spec.Output.To)
+
+	is := objects["ImageStream/app"].(*ImageStream)
+	assert.Nil(t, is.Spec.Tags)
+
+	dc := objects["DeploymentConfig/app"].(*DeploymentConfig)
+	assert.Equal(t, "app:latest", dc.Spec.Template.Spec.Containers[0].Image)
+
+	_, ok := objects["BuildConfig/local"]
+	assert.False(t, ok)
+}
+
+func TestTransformReplicas(t *testing.T) {
+	objects := transform(t, `
+version: '3'
+services:
+  web:
+    image: nginx
+    deploy:
+      replicas: 3
+`)
+
+	dc := objects["DeploymentConfig/web"].(*DeploymentConfig)
+	assert.Equal(t, 3, dc.Spec.Replicas)
+}
+
+func TestSerialization(t *testing.T) {
+	objects := transform(t, `
+version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+`)
+
+	data, err := yaml.Marshal(objects["Route/web"])
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "kind: Route")
+	assert.Contains(t, string(data), "targetPort: 80")
+
+	data, err = yaml.Marshal(objects["DeploymentConfig/web"])
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "apiVersion: v1")
+	assert.Contains(t, string(data), "name: web:latest")
+}
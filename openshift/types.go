@@ -0,0 +1,147 @@
+package openshift
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/util/intstr"
+)
+
+// The OpenShift API types are not vendored, the structures below only hold the
+// subset of the OpenShift v1 API that the conversion generates, with the same
+// serialized form.
+
+// DeploymentConfig holds an OpenShift v1 DeploymentConfig.
+type DeploymentConfig struct {
+	unversioned.TypeMeta `json:",inline"`
+	api.ObjectMeta       `json:"metadata,omitempty"`
+
+	Spec DeploymentConfigSpec `json:"spec"`
+}
+
+// DeploymentConfigSpec holds the desired state of a DeploymentConfig.
+type DeploymentConfigSpec struct {
+	Strategy DeploymentStrategy        `json:"strategy"`
+	Triggers []DeploymentTriggerPolicy `json:"triggers,omitempty"`
+	Replicas int                       `json:"replicas"`
+	Selector map[string]string         `json:"selector,omitempty"`
+	Template *api.PodTemplateSpec      `json:"template,omitempty"`
+}
+
+// DeploymentStrategy describes how a DeploymentConfig rolls out new pods.
+type DeploymentStrategy struct {
+	Type string `json:"type,omitempty"`
+}
+
+// DeploymentTriggerPolicy describes a policy that triggers a new deployment.
+type DeploymentTriggerPolicy struct {
+	Type              string                              `json:"type,omitempty"`
+	ImageChangeParams *DeploymentTriggerImageChangeParams `json:"imageChangeParams,omitempty"`
+}
+
+// DeploymentTriggerImageChangeParams holds the parameters of an ImageChange trigger.
+type DeploymentTriggerImageChangeParams struct {
+	Automatic      bool                `json:"automatic,omitempty"`
+	ContainerNames []string            `json:"containerNames,omitempty"`
+	From           api.ObjectReference `json:"from"`
+}
+
+// ImageStream holds an OpenShift v1 ImageStream.
+type ImageStream struct {
+	unversioned.TypeMeta `json:",inline"`
+	api.ObjectMeta       `json:"metadata,omitempty"`
+
+	Spec ImageStreamSpec `json:"spec"`
+}
+
+// ImageStreamSpec holds the tags tracked by an ImageStream.
+type ImageStreamSpec struct {
+	Tags []TagReference `json:"tags,omitempty"`
+}
+
+// TagReference maps an ImageStream tag to the image it imports.
+type TagReference struct {
+	Name string               `json:"name"`
+	From *api.ObjectReference `json:"from,omitempty"`
+}
+
+// Route holds an OpenShift v1 Route.
+type Route struct {
+	unversioned.TypeMeta `json:",inline"`
+	api.ObjectMeta       `json:"metadata,omitempty"`
+
+	Spec RouteSpec `json:"spec"`
+}
+
+// RouteSpec holds the target of a Route.
+type RouteSpec struct {
+	Host string              `json:"host,omitempty"`
+	To   api.ObjectReference `json:"to"`
+	Port *RoutePort          `json:"port,omitempty"`
+}
+
+// RoutePort selects the service port a Route sends traffic to.
+type RoutePort struct {
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+// BuildConfig holds an OpenShift v1 BuildConfig.
+type BuildConfig struct {
+	unversioned.TypeMeta `json:",inline"`
+	api.ObjectMeta       `json:"metadata,omitempty"`
+
+	Spec BuildConfigSpec `json:"spec"`
+}
+
+// BuildConfigSpec describes how an image is built and where it is pushed.
+type BuildConfigSpec struct {
+	Triggers []BuildTriggerPolicy `json:"triggers,omitempty"`
+	Source   BuildSource          `json:"source"`
+	Strategy BuildStrategy        `json:"strategy"`
+	Output   BuildOutput          `json:"output"`
+}
+
+// BuildTriggerPolicy describes a policy that triggers a new build.
+type BuildTriggerPolicy struct {
+	Type string `json:"type"`
+}
+
+// BuildSource holds the git repository a build starts from.
+type BuildSource struct {
+	Type       string          `json:"type"`
+	Git        *GitBuildSource `json:"git,omitempty"`
+	ContextDir string          `json:"contextDir,omitempty"`
+}
+
+// GitBuildSource holds a git repository URI and the ref to build.
+type GitBuildSource struct {
+	URI string `json:"uri"`
+	Ref string `json:"ref,omitempty"`
+}
+
+// BuildStrategy holds the strategy used to build the image.
+type BuildStrategy struct {
+	Type           string               `json:"type"`
+	DockerStrategy *DockerBuildStrategy `json:"dockerStrategy,omitempty"`
+}
+
+// DockerBuildStrategy holds the options of a Docker build.
+type DockerBuildStrategy struct {
+	DockerfilePath string `json:"dockerfilePath,omitempty"`
+}
+
+// BuildOutput holds the image a build pushes to.
+type BuildOutput struct {
+	To *api.ObjectReference `json:"to,omitempty"`
+}
+
+// GetObjectKind implements runtime.Object.
+func (obj *DeploymentConfig) GetObjectKind() unversioned.ObjectKind { return &obj.TypeMeta }
+
+// GetObjectKind implements runtime.Object.
+func (obj *ImageStream) GetObjectKind() unversioned.ObjectKind { return &obj.TypeMeta }
+
+// GetObjectKind implements runtime.Object.
+func (obj *Route) GetObjectKind() unversioned.ObjectKind { return &obj.TypeMeta }
+
+// GetObjectKind implements runtime.Object.
+func (obj *BuildConfig) GetObjectKind() unversioned.ObjectKind { return &obj.TypeMeta }
@@ -0,0 +1,118 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "github.com/cloudfoundry-incubator/candiedyaml"
+	"github.com/docker/libcompose/config"
+	"github.com/docker/libcompose/utils"
+)
+
+// The keys of the services introduced after version 2.0 of the file format,
+// by the version introducing them, and the keys version 3 dropped.
+var (
+	serviceKeyVersions = map[string]string{
+		"healthcheck": "2.1",
+		"deploy":      "3.0",
+		"secrets":     "3.1",
+		"configs":     "3.3",
+	}
+	v2OnlyKeys = []string{"cpu_quota", "cpu_shares", "cpuset", "mem_limit", "memswap_limit", "volume_driver", "volumes_from"}
+)
+
+// MarshalConfig marshals the merged and interpolated configuration of the
+// project into a compose file of the lowest version supporting its keys: its
+// enabled services, and the services they depend on, with their x- keys, its
+// volumes, networks, secrets and configs, and its top level x- keys. It fails
+// when the services set keys version 3 dropped along with keys it introduced.
+func (p *Project) MarshalConfig() ([]byte, error) {
+	names := p.EnabledServices()
+	services := config.RawServiceMap{}
+	for _, name := range names {
+		serviceConfig, _ := p.ServiceConfigs.Get(name)
+
+		var service config.RawService
+		if err := utils.Convert(serviceConfig, &service); err != nil {
+			return nil, err
+		}
+		for key, value := range service {
+			if isEmpty(value) {
+				delete(service, key)
+			}
+		}
+		for key, value := range serviceConfig.Extensions {
+			service[key] = value
+		}
+		services[name] = service
+	}
+
+	version, requiredBy := configVersion(names, services, p.Secrets, p.Configs)
+	if version >= "3" {
+		for _, name := range names {
+			var unsupported []string
+			for _, key := range v2OnlyKeys {
+				if _, ok := services[name][key]; ok {
+					unsupported = append(unsupported, key)
+				}
+			}
+			if len(unsupported) > 0 {
+				return nil, fmt.Errorf("Service %s: %s not supported by version %s files, which %s requires", name, strings.Join(unsupported, ", "), version, requiredBy)
+			}
+		}
+	}
+
+	var data map[string]interface{}
+	if err := utils.Convert(&config.Config{
+		Version:  version,
+		Services: services,
+		Volumes:  p.VolumeConfigs,
+		Networks: p.NetworkConfigs,
+		Secrets:  p.Secrets,
+		Configs:  p.Configs,
+	}, &data); err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extensions {
+		data[key] = value
+	}
+
+	return yaml.Marshal(data)
+}
+
+// configVersion returns the lowest version of the file format supporting the
+// keys of the specified services, and the secrets and configs, of a project,
+// and the key requiring it.
+func configVersion(names []string, services config.RawServiceMap, secrets, configs map[string]*config.FileConfig) (string, string) {
+	version, requiredBy := "2", ""
+	if len(secrets) > 0 {
+		version, requiredBy = "3.1", "the top level secrets key"
+	}
+	if len(configs) > 0 {
+		version, requiredBy = "3.3", "the top level configs key"
+	}
+	for _, name := range names {
+		for key := range services[name] {
+			if keyVersion, ok := serviceKeyVersions[key]; ok && keyVersion > version {
+				version, requiredBy = keyVersion, fmt.Sprintf("the %s key of service %s", key, name)
+			}
+		}
+	}
+	return version, requiredBy
+}
+
+// isEmpty returns whether a value is a map without keys, or whose values are
+// all empty maps, which it removes: the structs of ServiceConfig without
+// values, like its deploy section, are marshaled as such maps.
+func isEmpty(value interface{}) bool {
+	values, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	for key, value := range values {
+		if isEmpty(value) {
+			delete(values, key)
+		}
+	}
+	return len(values) == 0
+}
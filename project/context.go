@@ -12,6 +12,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/libcompose/config"
 	"github.com/docker/libcompose/logger"
+	"github.com/docker/libcompose/lookup"
 )
 
 var projectRegexp = regexp.MustCompile("[^a-zA-Z0-9_.-]")
@@ -28,6 +29,7 @@ type Context struct {
 	ResourceLookup      config.ResourceLookup
 	LoggerFactory       logger.Factory
 	IgnoreMissingConfig bool
+	Profiles            []string
 	Project             *Project
 }
 
@@ -50,6 +52,20 @@ func (c *Context) readComposeFiles() error {
 	}
 
 	for _, composeFile := range c.ComposeFiles {
+		// Handle HTTP(S) URLs and git repositories
+		if lookup.IsRemote(composeFile) {
+			if c.ResourceLookup == nil {
+				return fmt.Errorf("Can not open the compose file %s no mechanism provided to load files", composeFile)
+			}
+			composeBytes, _, err := c.ResourceLookup.Lookup(composeFile, "")
+			if err != nil {
+				logrus.Errorf("Failed to open the compose file: %s", composeFile)
+				return err
+			}
+			c.ComposeBytes = append(c.ComposeBytes, composeBytes)
+			continue
+		}
+
 		composeBytes, err := ioutil.ReadFile(composeFile)
 		if err != nil && !os.IsNotExist(err) {
 			logrus.Errorf("Failed to open the compose file: %s", composeFile)
@@ -89,8 +105,9 @@ func (c *Context) lookupProjectName() (string, error) {
 		return envProject, nil
 	}
 
+	// remote compose files are named after the working directory
 	file := "."
-	if len(c.ComposeFiles) > 0 {
+	if len(c.ComposeFiles) > 0 && !lookup.IsRemote(c.ComposeFiles[0]) {
 		file = c.ComposeFiles[0]
 	}
 
@@ -0,0 +1,56 @@
+package project
+
+import (
+	"sort"
+
+	"github.com/docker/libcompose/utils"
+)
+
+// IsEnabled returns whether the specified service is enabled: it has no
+// profiles, or one of its profiles is enabled in the context of the project.
+func (p *Project) IsEnabled(name string) bool {
+	serviceConfig, ok := p.ServiceConfigs.Get(name)
+	if !ok {
+		return false
+	}
+	if len(serviceConfig.Profiles) == 0 {
+		return true
+	}
+	for _, profile := range serviceConfig.Profiles {
+		if utils.Contains(p.context.Profiles, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnabledServices returns the sorted names of the services of the project
+// that are enabled or specified, and of the services they depend on.
+func (p *Project) EnabledServices(services ...string) []string {
+	enabled := map[string]bool{}
+	var queue []string
+	for _, name := range p.ServiceConfigs.Keys() {
+		if p.IsEnabled(name) || utils.Contains(services, name) {
+			enabled[name] = true
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		serviceConfig, _ := p.ServiceConfigs.Get(queue[0])
+		queue = queue[1:]
+		for _, dependency := range dependentServices(serviceConfig) {
+			if !enabled[dependency.Target] && p.ServiceConfigs.Has(dependency.Target) {
+				enabled[dependency.Target] = true
+				queue = append(queue, dependency.Target)
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -24,6 +24,9 @@ type Project struct {
 	ServiceConfigs *config.ServiceConfigs
 	VolumeConfigs  map[string]*config.VolumeConfig
 	NetworkConfigs map[string]*config.NetworkConfig
+	Secrets        map[string]*config.FileConfig
+	Configs        map[string]*config.FileConfig
+	Extensions     map[string]interface{}
 	Files          []string
 	ReloadCallback func() error
 	ParseOptions   *config.ParseOptions
@@ -46,6 +49,9 @@ func NewProject(context *Context, runtime RuntimeProject, parseOptions *config.P
 		ServiceConfigs: config.NewServiceConfigs(),
 		VolumeConfigs:  make(map[string]*config.VolumeConfig),
 		NetworkConfigs: make(map[string]*config.NetworkConfig),
+		Secrets:        make(map[string]*config.FileConfig),
+		Configs:        make(map[string]*config.FileConfig),
+		Extensions:     make(map[string]interface{}),
 	}
 
 	if context.LoggerFactory == nil {
@@ -164,6 +170,30 @@ func (p *Project) load(file string, bytes []byte) error {
 
 	p.configVersion = version
 
+	topLevelConfig, err := config.ParseConfig(bytes)
+	if err != nil {
+		return err
+	}
+	for key, value := range topLevelConfig.Extensions {
+		p.Extensions[key] = value
+	}
+
+	secrets, err := config.ParseSecrets(p.context.ResourceLookup, file, bytes)
+	if err != nil {
+		return err
+	}
+	for name, secret := range secrets {
+		p.Secrets[name] = secret
+	}
+
+	configs, err := config.ParseConfigs(p.context.ResourceLookup, file, bytes)
+	if err != nil {
+		return err
+	}
+	for name, config := range configs {
+		p.Configs[name] = config
+	}
+
 	for name, config := range serviceConfigs {
 		err := p.AddConfig(name, config)
 		if err != nil {
@@ -197,6 +227,17 @@ func (p *Project) loadWrappers(wrappers map[string]*serviceWrapper, servicesToCo
 		wrappers[name] = wrapper
 	}
 
+	// the services they depend on are constructed too, even if disabled
+	for _, name := range servicesToConstruct {
+		for _, dep := range wrappers[name].service.DependentServices() {
+			if wrappers[dep.Target] == nil && p.ServiceConfigs.Has(dep.Target) {
+				if err := p.loadWrappers(wrappers, []string{dep.Target}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -564,7 +605,9 @@ func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[st
 
 	if start {
 		for _, name := range p.ServiceConfigs.Keys() {
-			wrapperList = append(wrapperList, name)
+			if selected[name] || p.IsEnabled(name) {
+				wrapperList = append(wrapperList, name)
+			}
 		}
 	} else {
 		for _, wrapper := range wrappers {
@@ -572,7 +615,11 @@ func (p *Project) traverse(start bool, selected map[string]bool, wrappers map[st
 				return err
 			}
 		}
-		wrapperList = p.reload
+		for _, name := range p.reload {
+			if wrappers[name] != nil || selected[name] || p.IsEnabled(name) {
+				wrapperList = append(wrapperList, name)
+			}
+		}
 	}
 
 	p.loadWrappers(wrappers, wrapperList)
@@ -56,6 +56,29 @@ func (t *TestServiceFactory) Create(project *Project, name string, serviceConfig
 	}, nil
 }
 
+// DependentTestServiceFactory creates test services depending on the services
+// they link, like the services of the docker project.
+type DependentTestServiceFactory struct {
+	TestServiceFactory
+}
+
+type DependentTestService struct {
+	*TestService
+	project *Project
+}
+
+func (t *DependentTestService) DependentServices() []ServiceRelationship {
+	return DefaultDependentServices(t.project, t)
+}
+
+func (t *DependentTestServiceFactory) Create(project *Project, name string, serviceConfig *config.ServiceConfig) (Service, error) {
+	service, _ := t.TestServiceFactory.Create(project, name, serviceConfig)
+	return &DependentTestService{
+		TestService: service.(*TestService),
+		project:     project,
+	}, nil
+}
+
 func TestTwoCall(t *testing.T) {
 	factory := &TestServiceFactory{
 		Counts: map[string]int{},
@@ -110,6 +133,26 @@ func TestParseWithGoodContent(t *testing.T) {
 	}
 }
 
+func TestParseWithExtensions(t *testing.T) {
+	p := NewProject(&Context{
+		ComposeBytes: [][]byte{
+			[]byte("x-owner: web-team\nweb:\n  image: foo"),
+		},
+	}, nil, nil)
+
+	if err := p.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.ServiceConfigs.Has("x-owner") {
+		t.Fatal("Extension parsed as a service")
+	}
+
+	if p.Extensions["x-owner"] != "web-team" {
+		t.Fatal("Invalid extensions", p.Extensions)
+	}
+}
+
 type TestEnvironmentLookup struct {
 }
 
@@ -206,3 +249,153 @@ func TestParseWithMultipleComposeFiles(t *testing.T) {
 	assert.Equal(t, []string{"8000", "9000", "10000"}, multipleConfig.Ports)
 	assert.Equal(t, int64(40000000), multipleConfig.MemLimit)
 }
+
+func TestMarshalConfig(t *testing.T) {
+	p := NewProject(&Context{
+		ComposeBytes: [][]byte{
+			[]byte(`version: '2'
+services:
+  web:
+    image: nginx
+    ports:
+      - 80
+    x-team: web
+volumes:
+  data: {}
+x-owner: platform`),
+			[]byte(`version: '2'
+services:
+  web:
+    environment:
+      DEBUG: "1"
+  db:
+    image: postgres
+    volumes:
+      - data:/var/lib/postgresql/data`),
+		},
+	}, nil, nil)
+	assert.Nil(t, p.Parse())
+
+	data, err := p.MarshalConfig()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "deploy")
+
+	marshaled := NewProject(&Context{ComposeBytes: [][]byte{data}}, nil, nil)
+	assert.Nil(t, marshaled.Parse())
+
+	for _, name := range []string{"web", "db"} {
+		service, _ := p.ServiceConfigs.Get(name)
+		marshaledService, ok := marshaled.ServiceConfigs.Get(name)
+		assert.True(t, ok)
+		assert.Equal(t, config.GetServiceHash(name, service), config.GetServiceHash(name, marshaledService), name)
+	}
+	assert.Equal(t, p.VolumeConfigs, marshaled.VolumeConfigs)
+	assert.Equal(t, p.Extensions, marshaled.Extensions)
+}
+
+func TestMarshalConfigVersion(t *testing.T) {
+	for _, c := range []struct {
+		config  string
+		version string
+	}{
+		{`version: '2'
+services:
+  web:
+    image: nginx
+    mem_limit: 1000000`, `version: "2"`},
+		{`version: '2.1'
+services:
+  web:
+    image: nginx
+    healthcheck:
+      test: curl -f http://localhost`, `version: "2.1"`},
+		{`version: '3'
+services:
+  web:
+    image: nginx
+    deploy:
+      replicas: 2`, `version: "3.0"`},
+		{`version: '3.3'
+services:
+  web:
+    image: nginx
+    configs:
+      - nginx
+configs:
+  nginx:
+    file: ./nginx.conf`, `version: "3.3"`},
+	} {
+		p := NewProject(&Context{ComposeBytes: [][]byte{[]byte(c.config)}}, nil, nil)
+		assert.Nil(t, p.Parse())
+
+		data, err := p.MarshalConfig()
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), c.version)
+
+		marshaled := NewProject(&Context{ComposeBytes: [][]byte{data}}, nil, nil)
+		assert.Nil(t, marshaled.Parse(), c.version)
+	}
+
+	// no version supports both the keys version 3 dropped and the ones it
+	// introduced
+	p := NewProject(&Context{ComposeBytes: [][]byte{[]byte(`version: '2'
+services:
+  web:
+    image: nginx
+    mem_limit: 1000000
+  worker:
+    image: worker
+    deploy:
+      replicas: 2`)}}, nil, nil)
+	assert.Nil(t, p.Parse())
+
+	_, err := p.MarshalConfig()
+	assert.NotNil(t, err)
+	assert.Equal(t, "Service web: mem_limit not supported by version 3.0 files, which the deploy key of service worker requires", err.Error())
+}
+
+func TestProfiles(t *testing.T) {
+	factory := &DependentTestServiceFactory{
+		TestServiceFactory{Counts: map[string]int{}},
+	}
+
+	p := NewProject(&Context{
+		ServiceFactory: factory,
+		ComposeBytes: [][]byte{[]byte(`version: '2.1'
+services:
+  web:
+    image: nginx
+    links:
+      - db
+  db:
+    image: postgres
+    profiles: [storage]
+  admin:
+    image: adminer
+    profiles: [debug, tools]`)},
+	}, nil, nil)
+	assert.Nil(t, p.Parse())
+
+	assert.True(t, p.IsEnabled("web"))
+	assert.False(t, p.IsEnabled("db"))
+	assert.False(t, p.IsEnabled("admin"))
+	assert.Equal(t, []string{"db", "web"}, p.EnabledServices())
+	assert.Equal(t, []string{"admin", "db", "web"}, p.EnabledServices("admin"))
+
+	assert.Nil(t, p.Create(context.Background(), options.Create{}))
+	// db is disabled, but web links it
+	assert.Equal(t, map[string]int{"web.create": 1, "db.create": 1}, factory.Counts)
+
+	// the specified services are constructed even if disabled
+	assert.Nil(t, p.Create(context.Background(), options.Create{}, "admin"))
+	assert.Equal(t, 1, factory.Counts["admin.create"])
+
+	data, err := p.MarshalConfig()
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "postgres")
+	assert.NotContains(t, string(data), "adminer")
+
+	p.context.Profiles = []string{"tools"}
+	assert.True(t, p.IsEnabled("admin"))
+	assert.Equal(t, []string{"admin", "db", "web"}, p.EnabledServices())
+}
@@ -2,12 +2,19 @@ package project
 
 import (
 	"strings"
+
+	"github.com/docker/libcompose/config"
 )
 
 // DefaultDependentServices return the dependent services (as an array of ServiceRelationship)
 // for the specified project and service. It looks for : links, volumesFrom, net and ipc configuration.
 func DefaultDependentServices(p *Project, s Service) []ServiceRelationship {
-	config := s.Config()
+	return dependentServices(s.Config())
+}
+
+// dependentServices returns the services the specified service config
+// depends on: its links, volumes_from and depends_on.
+func dependentServices(config *config.ServiceConfig) []ServiceRelationship {
 	if config == nil {
 		return []ServiceRelationship{}
 	}
@@ -20,6 +20,14 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	schemaV21, err := ioutil.ReadFile("./script/config_schema_v2.1.json")
+	if err != nil {
+		panic(err)
+	}
+	schemaV3, err := ioutil.ReadFile("./script/config_schema_v3.json")
+	if err != nil {
+		panic(err)
+	}
 
 	inlinedFile, err := os.Create("config/schema.go")
 	if err != nil {
@@ -27,8 +35,10 @@ func main() {
 	}
 
 	err = t.Execute(inlinedFile, map[string]string{
-		"schemaV1": string(schemaV1),
-		"schemaV2": string(schemaV2),
+		"schemaV1":  string(schemaV1),
+		"schemaV2":  string(schemaV2),
+		"schemaV21": string(schemaV21),
+		"schemaV3":  string(schemaV3),
 	})
 
 	if err != nil {
@@ -3,3 +3,7 @@ package config
 var schemaV1 = `{{.schemaV1}}`
 
 var schemaV2 = `{{.schemaV2}}`
+
+var schemaV21 = `{{.schemaV21}}`
+
+var schemaV3 = `{{.schemaV3}}`
@@ -44,6 +44,7 @@ clone git bitbucket.org/bertimus9/systemstat 1468fd0db20598383c9393cccaa547de6ad
 clone hg bitbucket.org/ww/goautoneg 75cd24fc2f2c2a2088577d12123ddee5f54e0675
 clone git github.com/beorn7/perks b965b613227fddccbfffe13eae360ed3fa822f8d
 clone git github.com/blang/semver 31b736133b98f26d5e078ec9eb591666edfd091f
+clone git github.com/docker/spdystream 449fdfce4d962303d702fec724ef0ad181c92528
 clone git github.com/google/cadvisor 546a3771589bdb356777c646c6eca24914fdd48b
 clone git github.com/emicklei/go-restful 777bb3f19bcafe2575ffb2a3e46af92509ae9594
 clone git github.com/ghodss/yaml 73d445a93680fa1a78ae23a5839bad48f32ba1ee